@@ -0,0 +1,305 @@
+/*
+	Date	:	18/10/2026
+	File	:	api.go
+	Overview: 	Api provides an HTTP server to query the metrics stored in the
+				database. Every query endpoint answers JSON and uses the same
+				query functions of the menu, from toolset package. /metrics
+				answers the latest values in Prometheus format.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/metrics"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/toolset"
+
+	"github.com/boltdb/bolt"
+)
+
+// AggregateValue type - one statistic of one variable, answer of aggregate
+// requests with a fn parameter
+type AggregateValue struct {
+	Variable string  `json:"variable"`
+	Function string  `json:"function"`
+	Value    float64 `json:"value"`
+}
+
+// NewHandler - Return an HTTP handler serving metrics queries over db
+func NewHandler(db *bolt.DB) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/metrics/last", func(w http.ResponseWriter, r *http.Request) {
+		handleLast(db, w, r)
+	})
+	mux.HandleFunc("/metrics/range", func(w http.ResponseWriter, r *http.Request) {
+		handleRange(db, w, r)
+	})
+	mux.HandleFunc("/metrics/aggregate", func(w http.ResponseWriter, r *http.Request) {
+		handleAggregate(db, w, r)
+	})
+
+	// Latest values and counters of the collectors, for Prometheus
+	mux.Handle("/metrics", metrics.Handler())
+
+	// New entries as they are written
+	mux.HandleFunc("/stream/sse", func(w http.ResponseWriter, r *http.Request) {
+		handleSSE(db, w, r)
+	})
+	mux.HandleFunc("/stream/ws", func(w http.ResponseWriter, r *http.Request) {
+		handleWebSocket(db, w, r)
+	})
+
+	return mux
+}
+
+// ListenAndServe - Serve metrics queries over db on address addr (e.g. :8080)
+func ListenAndServe(addr string, db *bolt.DB) error {
+	return http.ListenAndServe(addr, NewHandler(db))
+}
+
+// handleLast - GET /metrics/last?n=10&vars=c,r,1&device=greenhouse
+func handleLast(db *bolt.DB, w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	n, err := strconv.Atoi(r.URL.Query().Get("n"))
+
+	if err != nil || n <= 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("[API] - Parameter n must be a positive number"))
+		return
+	}
+
+	vars, err := queryVars(db, r)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err := checkVars(vars); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	series, err := toolset.GetLastN(db, fmt.Sprintf("%d,%s", n, vars))
+
+	// Tables with less than n entries are not an internal error
+	if err == toolset.ErrNotEnoughMetrics {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, series)
+}
+
+// handleRange - GET /metrics/range?from=...&to=...&vars=c,r,1
+func handleRange(db *bolt.DB, w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	from, err := parseTime(r.URL.Query().Get("from"))
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Without to the range goes until now
+	to := time.Now()
+
+	if r.URL.Query().Get("to") != "" {
+		to, err = parseTime(r.URL.Query().Get("to"))
+
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	vars, err := queryVars(db, r)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err := checkVars(vars); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	series, err := toolset.GetRange(db, from, to, vars)
+
+	// Empty ranges are an empty list, not an error
+	if err == toolset.ErrNoMetrics {
+		series, err = []toolset.Series{}, nil
+	}
+
+	if err == toolset.ErrReversedRange {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, series)
+}
+
+// handleAggregate - GET /metrics/aggregate?fn=p95&vars=c,r. Without fn every
+// statistic of each variable is returned.
+func handleAggregate(db *bolt.DB, w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	fn := r.URL.Query().Get("fn")
+
+	// Validate function before reading anything
+	if fn != "" {
+		if _, err := (toolset.Aggregate{}).Get(fn); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	vars, err := queryVars(db, r)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err := checkVars(vars); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	aggregates, err := toolset.GetAggregate(db, vars)
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if fn == "" {
+		writeJSON(w, aggregates)
+		return
+	}
+
+	values := []AggregateValue{}
+
+	for _, a := range aggregates {
+		value, _ := a.Get(fn)
+		values = append(values, AggregateValue{Variable: a.Variable, Function: strings.ToLower(fn), Value: value})
+	}
+
+	writeJSON(w, values)
+}
+
+// allowGet - Answer 405 to anything but GET requests
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("[API] - Method %s not allowed", r.Method))
+		return false
+	}
+
+	return true
+}
+
+// queryVars - Return the vars parameter of a request, or every registered variable
+// but labeled ones (see collector.BaseCodes). With a device parameter only variables
+// of that fleet device are returned, or an error when db has no table of the device.
+func queryVars(db *bolt.DB, r *http.Request) (string, error) {
+	vars := r.URL.Query().Get("vars")
+
+	if device := r.URL.Query().Get("device"); device != "" {
+		return toolset.DeviceCodes(db, vars, device)
+	}
+
+	if vars == "" {
+		return collector.BaseCodes(), nil
+	}
+
+	return vars, nil
+}
+
+// checkVars - Return an error naming the codes of vars that are not registered
+// variables, so a query with typos is not answered with nothing
+func checkVars(vars string) error {
+	var unknown []string
+
+	if vars == "" {
+		return fmt.Errorf("[API] - No variables to query")
+	}
+
+	for _, code := range strings.Split(vars, ",") {
+		if _, _, ok := collector.Lookup(code); !ok {
+			unknown = append(unknown, code)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("[API] - Unknown variables: %s", strings.Join(unknown, ","))
+	}
+
+	return nil
+}
+
+// parseTime - Parse an instant given as RFC 3339, Unix seconds or any format
+// accepted by toolset.ParseTimeArg
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("[API] - Missing time parameter")
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	return toolset.ParseTimeArg(value)
+}
+
+// writeJSON - Answer with data encoded as json
+func writeJSON(w http.ResponseWriter, data interface{}) {
+	dataBytes, err := json.Marshal(data)
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("[API] - Error encoding answer: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(dataBytes)
+}
+
+// writeError - Answer with an error status and a json error message
+func writeError(w http.ResponseWriter, status int, err error) {
+	errorBytes, _ := json.Marshal(map[string]string{"error": err.Error()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(errorBytes)
+}
@@ -0,0 +1,94 @@
+/*
+	Date	:	18/10/2026
+	File	:	api_test.go
+	Overview: 	Tests of the answers of query endpoints to the variables asked.
+*/
+
+package api
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestQueryVariables(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte("DB"))
+		return err
+	})
+
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	// Variable a1 with two values, an hour ago and now
+	collector.Register(collector.NewStored("TEST_API", []collector.Variable{{Code: "a1", Key: "v"}}))
+	defer collector.Unregister("TEST_API")
+
+	if err := database.CreateTable(db, "TEST_API"); err != nil {
+		t.Fatalf("CreateTable error = %v", err)
+	}
+
+	now := time.Now()
+	entries := []database.Entry{
+		{Time: now.Add(-time.Hour), Values: map[string]float64{"v": 1}},
+		{Time: now, Values: map[string]float64{"v": 2}},
+	}
+
+	if err := database.AddEntries(db, "TEST_API", entries); err != nil {
+		t.Fatalf("AddEntries error = %v", err)
+	}
+
+	// Range limits as Unix seconds, from two hours ago and to the minute before
+	from := strconv.FormatInt(now.Add(-2*time.Hour).Unix(), 10)
+	before := strconv.FormatInt(now.Add(-2*time.Hour-time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		body   string
+	}{
+		{"last", "/metrics/last?n=2&vars=a1", 200, `"variable":"a1"`},
+		{"last of unknown variables", "/metrics/last?n=2&vars=zz,yy", 400, "zz,yy"},
+		{"last of some unknown variables", "/metrics/last?n=2&vars=a1,zz", 400, "Unknown variables: zz"},
+		{"aggregate", "/metrics/aggregate?fn=count&vars=a1", 200, `"value":2`},
+		{"aggregate of unknown variables", "/metrics/aggregate?fn=count&vars=zz", 400, "zz"},
+		{"aggregate of every statistic", "/metrics/aggregate?vars=a1", 200, `"count":2`},
+		{"range", "/metrics/range?vars=a1&from=" + from, 200, `"variable":"a1"`},
+		{"range of unknown variables", "/metrics/range?vars=zz&from=" + from, 400, "zz"},
+		{"reversed range", "/metrics/range?vars=a1&from=" + from + "&to=" + before, 400, "after its end"},
+	}
+
+	handler := NewHandler(db)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("body = %s, want it to contain %s", w.Body.String(), tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+/*
+	Date	:	18/10/2026
+	File	:	stream.go
+	Overview: 	Stream serves new entries as they are written, through
+				Server-Sent Events and WebSocket. Each client subscribes to
+				the stream hub with its own variables filter and buffer.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/stream"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/toolset"
+
+	"github.com/boltdb/bolt"
+	"github.com/gorilla/websocket"
+)
+
+// keepAlive - time between keep alive messages sent to idle clients
+const keepAlive = 15 * time.Second
+
+// AllowedOrigins - origins, besides the API own one, of the pages allowed to open
+// a WebSocket stream (e.g. https://wallboard.local). A * allows every origin.
+var AllowedOrigins []string
+
+// upgrader - upgrades HTTP requests to WebSocket from the allowed origins
+var upgrader = websocket.Upgrader{
+	CheckOrigin: checkOrigin,
+}
+
+// checkOrigin - Report whether the page opening a WebSocket stream comes from the
+// API own origin or from one of AllowedOrigins
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+
+	// Clients other than browsers send no origin
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+
+	if err != nil {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, allowed := range AllowedOrigins {
+		allowed = strings.TrimSuffix(strings.TrimSpace(allowed), "/")
+
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// streamVars - Return the variables asked by a stream request, none meaning all.
+// With a device parameter only variables of that fleet device are sent, or an
+// error is returned when db has no table of the device.
+func streamVars(db *bolt.DB, r *http.Request) ([]string, error) {
+	vars := r.URL.Query().Get("vars")
+
+	if device := r.URL.Query().Get("device"); device != "" {
+		var err error
+
+		if vars, err = toolset.DeviceCodes(db, vars, device); err != nil {
+			return nil, err
+		}
+
+		// A device without variables matches nothing, not everything
+		if vars == "" {
+			return []string{""}, nil
+		}
+	}
+
+	if vars == "" {
+		return nil, nil
+	}
+
+	return strings.Split(vars, ","), nil
+}
+
+// handleSSE - GET /stream/sse?vars=c,1 sends every new entry as an event
+func handleSSE(db *bolt.DB, w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	vars, err := streamVars(db, r)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("[API] - Streaming not supported"))
+		return
+	}
+
+	subscription := stream.DefaultHub.Subscribe(vars, stream.DefaultBuffer)
+	defer stream.DefaultHub.Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-subscription.Events:
+			eventBytes, err := json.Marshal(event)
+
+			if err != nil {
+				continue
+			}
+
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", strings.ToLower(event.Table), eventBytes)
+			flusher.Flush()
+
+		case <-ticker.C:
+			// Comment lines keep proxies from closing idle connections
+			fmt.Fprintf(w, ": keep-alive\n\n")
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleWebSocket - GET /stream/ws?vars=c,1 sends every new entry as a JSON message
+func handleWebSocket(db *bolt.DB, w http.ResponseWriter, r *http.Request) {
+	// Unknown devices are answered before upgrading
+	vars, err := streamVars(db, r)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+
+	// Upgrade already answered the client
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	subscription := stream.DefaultHub.Subscribe(vars, stream.DefaultBuffer)
+	defer stream.DefaultHub.Unsubscribe(subscription)
+
+	// Read until the client goes away, answering control messages on the way
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-subscription.Events:
+			conn.SetWriteDeadline(time.Now().Add(keepAlive))
+
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(keepAlive))
+
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+
+		case <-closed:
+			return
+		}
+	}
+}
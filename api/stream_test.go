@@ -0,0 +1,47 @@
+/*
+	Date	:	18/10/2026
+	File	:	stream_test.go
+	Overview: 	Tests of the origins allowed to open WebSocket streams.
+*/
+
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		allowed []string
+		want    bool
+	}{
+		{name: "no origin", want: true},
+		{name: "same origin", origin: "http://ubi.local:8080", want: true},
+		{name: "other origin", origin: "http://evil.example", want: false},
+		{name: "other port", origin: "http://ubi.local:9090", want: false},
+		{name: "allowed origin", origin: "https://wallboard.local", allowed: []string{"https://wallboard.local/"}, want: true},
+		{name: "origin not in the list", origin: "https://other.local", allowed: []string{"https://wallboard.local"}, want: false},
+		{name: "every origin", origin: "http://evil.example", allowed: []string{"*"}, want: true},
+		{name: "invalid origin", origin: "http://%zz", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AllowedOrigins = tt.allowed
+			defer func() { AllowedOrigins = nil }()
+
+			r := httptest.NewRequest("GET", "http://ubi.local:8080/stream/ws", nil)
+
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			if got := checkOrigin(r); got != tt.want {
+				t.Errorf("checkOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+/*
+	Date	:	18/10/2026
+	File	:	cgroup.go
+	Overview: 	Cgroup reads CPU and memory usage and limits of the cgroup
+				(v1 or v2) of this process. Inside a container the OS
+				collector reports CPU and RAM relative to the container
+				quota instead of the host numbers.
+*/
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CgroupMode type - when CPU and RAM are read from the cgroup
+type CgroupMode string
+
+// Cgroup modes: auto reads the cgroup when it has a CPU or memory limit, on
+// always reads it and off always reads host numbers
+const (
+	CgroupAuto CgroupMode = "auto"
+	CgroupOn   CgroupMode = "on"
+	CgroupOff  CgroupMode = "off"
+)
+
+// cgroupRoot - where cgroup filesystems are mounted
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupUnlimited - memory limits of cgroup v1 at or above this mean no limit
+const cgroupUnlimited = 1 << 62
+
+// cgroup type - files of the cgroup of this process and the previous CPU reading
+type cgroup struct {
+	version   int
+	cpuDir    string
+	cpuacct   string
+	memoryDir string
+	cpuUsage  float64
+	readAt    time.Time
+}
+
+// ParseCgroupMode - Parse a cgroup mode given by the user (auto, on or off)
+func ParseCgroupMode(value string) (CgroupMode, error) {
+	switch mode := CgroupMode(strings.ToLower(value)); mode {
+	case CgroupAuto, CgroupOn, CgroupOff:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("[Collector] - Invalid cgroup mode %q, use auto, on or off", value)
+}
+
+// openCgroup - Return the cgroup of this process for mode, nil when host numbers
+// should be read. Mode on fails when there is no readable cgroup.
+func openCgroup(mode CgroupMode) (*cgroup, error) {
+	if mode == CgroupOff {
+		return nil, nil
+	}
+
+	c, err := findCgroup()
+
+	if err != nil {
+		if mode == CgroupOn {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// Without limits the cgroup is the whole host
+	if mode == CgroupAuto && !c.limited() {
+		return nil, nil
+	}
+
+	// Read CPU usage now, so the first Collect gives a real usage
+	c.cpuUsage, err = c.readCPUUsage()
+	c.readAt = time.Now()
+
+	if err != nil {
+		if mode == CgroupOn {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return c, nil
+}
+
+// findCgroup - Find the cgroup files of this process from /proc/self/cgroup
+func findCgroup() (*cgroup, error) {
+	file, err := os.Open("/proc/self/cgroup")
+
+	if err != nil {
+		return nil, fmt.Errorf("[Collector] - Error reading cgroup: %v", err)
+	}
+	defer file.Close()
+
+	c := &cgroup{}
+	scanner := bufio.NewScanner(file)
+
+	// Lines look like 4:memory:/docker/abc (v1) or 0::/system.slice (v2)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+
+		if len(fields) < 3 {
+			continue
+		}
+
+		if fields[1] == "" {
+			if dir := cgroupDir("", fields[2], "cpu.stat"); dir != "" {
+				return &cgroup{version: 2, cpuDir: dir, cpuacct: dir, memoryDir: dir}, nil
+			}
+			continue
+		}
+
+		for _, controller := range strings.Split(fields[1], ",") {
+			switch controller {
+			case "cpu":
+				c.cpuDir = cgroupDir(fields[1], fields[2], "cpu.cfs_quota_us")
+			case "cpuacct":
+				c.cpuacct = cgroupDir(fields[1], fields[2], "cpuacct.usage")
+			case "memory":
+				c.memoryDir = cgroupDir(fields[1], fields[2], "memory.usage_in_bytes")
+			}
+		}
+	}
+
+	if c.cpuDir == "" || c.cpuacct == "" || c.memoryDir == "" {
+		return nil, fmt.Errorf("[Collector] - There is no readable cgroup")
+	}
+
+	c.version = 1
+	return c, nil
+}
+
+// cgroupDir - Return the directory of a cgroup with file. Inside a container the
+// cgroup is usually mounted as the root, so the root is tried after the path.
+func cgroupDir(controllers string, path string, file string) string {
+	var candidates []string
+
+	for _, mount := range []string{controllers, strings.Split(controllers, ",")[0]} {
+		base := filepath.Join(cgroupRoot, mount)
+		candidates = append(candidates, filepath.Join(base, path), base)
+	}
+
+	for _, dir := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+			return dir
+		}
+	}
+
+	return ""
+}
+
+// limited - Return true when the cgroup has a CPU or memory limit
+func (c *cgroup) limited() bool {
+	_, cpuLimited := c.cpuQuota()
+	_, memoryLimited := c.memoryLimit()
+
+	return cpuLimited || memoryLimited
+}
+
+// cpuQuota - Return the number of CPUs the cgroup can use, or false without quota
+func (c *cgroup) cpuQuota() (float64, bool) {
+	var quota, period float64
+	var err error
+
+	if c.version == 2 {
+		// cpu.max holds "max 100000" or "quota period"
+		fields := strings.Fields(readCgroupFile(c.cpuDir, "cpu.max"))
+
+		if len(fields) < 2 || fields[0] == "max" {
+			return 0, false
+		}
+
+		quota, err = strconv.ParseFloat(fields[0], 64)
+		if err == nil {
+			period, err = strconv.ParseFloat(fields[1], 64)
+		}
+	} else {
+		quota, err = strconv.ParseFloat(readCgroupFile(c.cpuDir, "cpu.cfs_quota_us"), 64)
+		if err == nil {
+			period, err = strconv.ParseFloat(readCgroupFile(c.cpuDir, "cpu.cfs_period_us"), 64)
+		}
+	}
+
+	if err != nil || quota <= 0 || period <= 0 {
+		return 0, false
+	}
+
+	return quota / period, true
+}
+
+// memoryLimit - Return the memory limit of the cgroup in bytes, or false without limit
+func (c *cgroup) memoryLimit() (float64, bool) {
+	file := "memory.limit_in_bytes"
+
+	if c.version == 2 {
+		file = "memory.max"
+	}
+
+	limit, err := strconv.ParseFloat(readCgroupFile(c.memoryDir, file), 64)
+
+	// memory.max holds max without limit, which fails to parse
+	if err != nil || limit <= 0 || limit >= cgroupUnlimited {
+		return 0, false
+	}
+
+	return limit, true
+}
+
+// readCPUUsage - Return CPU time used by the cgroup, in seconds
+func (c *cgroup) readCPUUsage() (float64, error) {
+	if c.version == 2 {
+		// cpu.stat holds lines like "usage_usec 123"
+		for _, line := range strings.Split(readCgroupFile(c.cpuacct, "cpu.stat"), "\n") {
+			fields := strings.Fields(line)
+
+			if len(fields) == 2 && fields[0] == "usage_usec" {
+				usec, err := strconv.ParseFloat(fields[1], 64)
+				return usec / 1e6, err
+			}
+		}
+
+		return 0, fmt.Errorf("[Collector] - There is no CPU usage in cgroup")
+	}
+
+	nsec, err := strconv.ParseFloat(readCgroupFile(c.cpuacct, "cpuacct.usage"), 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("[Collector] - Error reading cgroup CPU usage: %v", err)
+	}
+
+	return nsec / 1e9, nil
+}
+
+// readMemoryUsage - Return memory used by the cgroup, in bytes. Inactive page
+// cache can be reclaimed, so it is not counted as used, like docker stats does.
+func (c *cgroup) readMemoryUsage() (float64, error) {
+	file := "memory.usage_in_bytes"
+
+	if c.version == 2 {
+		file = "memory.current"
+	}
+
+	usage, err := strconv.ParseFloat(readCgroupFile(c.memoryDir, file), 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("[Collector] - Error reading cgroup memory usage: %v", err)
+	}
+
+	if inactive := c.inactiveFile(); inactive < usage {
+		usage = usage - inactive
+	}
+
+	return usage, nil
+}
+
+// inactiveFile - Return the inactive page cache of the cgroup in bytes, read from
+// memory.stat, or zero when it cant be read
+func (c *cgroup) inactiveFile() float64 {
+	key := "total_inactive_file"
+
+	if c.version == 2 {
+		key = "inactive_file"
+	}
+
+	// memory.stat holds lines like "inactive_file 123"
+	for _, line := range strings.Split(readCgroupFile(c.memoryDir, "memory.stat"), "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) == 2 && fields[0] == key {
+			if value, err := strconv.ParseFloat(fields[1], 64); err == nil {
+				return value
+			}
+		}
+	}
+
+	return 0
+}
+
+// points - Return CPU usage since the previous call, relative to the CPU quota,
+// and memory usage relative to the memory limit. Without a limit the host CPUs
+// and hostTotal bytes of RAM are used. Cgroups only account their whole CPU time,
+// so there is no usage per core nor time breakdown (see cpuTotalOnly).
+func (c *cgroup) points(hostTotal float64) ([]Point, error) {
+	usage, err := c.readCPUUsage()
+
+	if err != nil {
+		return nil, err
+	}
+
+	memoryUsage, err := c.readMemoryUsage()
+
+	if err != nil {
+		return nil, err
+	}
+
+	cores, ok := c.cpuQuota()
+
+	if !ok {
+		cores = float64(runtime.NumCPU())
+	}
+
+	limit, ok := c.memoryLimit()
+
+	if !ok || limit > hostTotal {
+		limit = hostTotal
+	}
+
+	now := time.Now()
+	var points []Point
+
+	if elapsed := now.Sub(c.readAt).Seconds(); elapsed > 0 {
+		points = append(points, Point{Key: "cpu", Value: clampPercent((usage - c.cpuUsage) / (elapsed * cores) * 100)})
+	}
+
+	c.cpuUsage = usage
+	c.readAt = now
+
+	points = append(points,
+		Point{Key: "totalRAM", Value: limit / mebibyte},
+		Point{Key: "usedRAM", Value: memoryUsage / mebibyte},
+		Point{Key: "memUsed", Value: memoryUsage},
+		Point{Key: "memTotal", Value: limit},
+		Point{Key: "memUsedPercent", Value: memoryUsage / limit * 100},
+		Point{Key: "memAvailable", Value: limit - memoryUsage},
+	)
+
+	return points, nil
+}
+
+// readCgroupFile - Return the trimmed content of file in dir, empty if it cant be read
+func readCgroupFile(dir string, file string) string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, file))
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
+
+// overridePoints - Return points with the values of overrides, replacing the
+// points with the same key
+func overridePoints(points []Point, overrides []Point) []Point {
+	index := make(map[string]int)
+
+	for i, p := range points {
+		index[p.Key] = i
+	}
+
+	for _, o := range overrides {
+		if i, ok := index[o.Key]; ok {
+			points[i] = o
+		} else {
+			points = append(points, o)
+		}
+	}
+
+	return points
+}
@@ -0,0 +1,306 @@
+/*
+	Date	:	18/10/2026
+	File	:	cgroup_test.go
+	Overview: 	Tests of the limits and usage read from cgroup v1 and v2 files,
+				and of the host numbers they replace.
+*/
+
+package collector
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakeCgroup - Return a cgroup of version with files written in a temporary
+// directory, used for CPU, CPU accounting and memory alike
+func fakeCgroup(t *testing.T, version int, files map[string]string) *cgroup {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	return &cgroup{version: version, cpuDir: dir, cpuacct: dir, memoryDir: dir}
+}
+
+func TestParseCgroupMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    CgroupMode
+		wantErr bool
+	}{
+		{value: "auto", want: CgroupAuto},
+		{value: "ON", want: CgroupOn},
+		{value: "off", want: CgroupOff},
+		{value: "", wantErr: true},
+		{value: "v2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseCgroupMode(tt.value)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCgroupMode(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCgroupMode(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCPUQuota(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		files   map[string]string
+		want    float64
+		wantOK  bool
+	}{
+		{"v2 quota", 2, map[string]string{"cpu.max": "150000 100000\n"}, 1.5, true},
+		{"v2 without quota", 2, map[string]string{"cpu.max": "max 100000"}, 0, false},
+		{"v2 without file", 2, nil, 0, false},
+		{"v2 invalid period", 2, map[string]string{"cpu.max": "50000 x"}, 0, false},
+		{"v1 quota", 1, map[string]string{"cpu.cfs_quota_us": "200000", "cpu.cfs_period_us": "100000"}, 2, true},
+		{"v1 without quota", 1, map[string]string{"cpu.cfs_quota_us": "-1", "cpu.cfs_period_us": "100000"}, 0, false},
+		{"v1 without period", 1, map[string]string{"cpu.cfs_quota_us": "50000"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fakeCgroup(t, tt.version, tt.files).cpuQuota()
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("cpuQuota() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		files   map[string]string
+		want    float64
+		wantOK  bool
+	}{
+		{"v2 limit", 2, map[string]string{"memory.max": "536870912\n"}, 536870912, true},
+		{"v2 without limit", 2, map[string]string{"memory.max": "max"}, 0, false},
+		{"v1 limit", 1, map[string]string{"memory.limit_in_bytes": "1073741824"}, 1073741824, true},
+		{"v1 without limit", 1, map[string]string{"memory.limit_in_bytes": "9223372036854771712"}, 0, false},
+		{"v1 without file", 1, nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fakeCgroup(t, tt.version, tt.files).memoryLimit()
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("memoryLimit() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestReadUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		files   map[string]string
+		cpu     float64
+		memory  float64
+		wantErr bool
+	}{
+		{
+			name:    "v2",
+			version: 2,
+			files:   map[string]string{"cpu.stat": "usage_usec 2500000\nuser_usec 2000000\n", "memory.current": "1048576"},
+			cpu:     2.5,
+			memory:  1048576,
+		},
+		{
+			name:    "v1",
+			version: 1,
+			files:   map[string]string{"cpuacct.usage": "3000000000", "memory.usage_in_bytes": "2097152"},
+			cpu:     3,
+			memory:  2097152,
+		},
+		{
+			name:    "v2 without inactive page cache",
+			version: 2,
+			files: map[string]string{
+				"cpu.stat":       "usage_usec 2500000",
+				"memory.current": "1048576",
+				"memory.stat":    "anon 262144\nfile 786432\ninactive_file 524288\nactive_file 262144\n",
+			},
+			cpu:    2.5,
+			memory: 524288,
+		},
+		{
+			name:    "v1 without inactive page cache",
+			version: 1,
+			files: map[string]string{
+				"cpuacct.usage":         "3000000000",
+				"memory.usage_in_bytes": "2097152",
+				"memory.stat":           "inactive_file 4096\ntotal_inactive_file 1048576\n",
+			},
+			cpu:    3,
+			memory: 1048576,
+		},
+		{
+			name:    "inactive page cache above usage",
+			version: 2,
+			files:   map[string]string{"cpu.stat": "usage_usec 2500000", "memory.current": "1048576", "memory.stat": "inactive_file 2097152"},
+			cpu:     2.5,
+			memory:  1048576,
+		},
+		{
+			name:    "v2 without CPU usage",
+			version: 2,
+			files:   map[string]string{"cpu.stat": "user_usec 2000000", "memory.current": "1048576"},
+			wantErr: true,
+		},
+		{
+			name:    "v1 without memory usage",
+			version: 1,
+			files:   map[string]string{"cpuacct.usage": "3000000000"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fakeCgroup(t, tt.version, tt.files)
+
+			cpu, cpuErr := c.readCPUUsage()
+			memory, memoryErr := c.readMemoryUsage()
+
+			if failed := cpuErr != nil || memoryErr != nil; failed != tt.wantErr {
+				t.Fatalf("errors = %v, %v, want error %v", cpuErr, memoryErr, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if cpu != tt.cpu || memory != tt.memory {
+				t.Errorf("usage = %v s, %v B, want %v s, %v B", cpu, memory, tt.cpu, tt.memory)
+			}
+		})
+	}
+}
+
+func TestCgroupPoints(t *testing.T) {
+	c := fakeCgroup(t, 2, map[string]string{
+		"cpu.stat":       "usage_usec 1000000",
+		"cpu.max":        "100000 100000",
+		"memory.current": "268435456",
+		"memory.max":     "1073741824",
+	})
+
+	// Memory limit above the host RAM is capped by it
+	tests := []struct {
+		name      string
+		hostTotal float64
+		total     float64
+		percent   float64
+	}{
+		{"limit of the cgroup", 4294967296, 1073741824, 25},
+		{"host has less RAM", 536870912, 536870912, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := c.points(tt.hostTotal)
+
+			if err != nil {
+				t.Fatalf("points error = %v", err)
+			}
+
+			values := make(map[string]float64)
+
+			for _, p := range points {
+				values[p.Key] = p.Value
+			}
+
+			want := map[string]float64{
+				"memUsed":        268435456,
+				"memTotal":       tt.total,
+				"usedRAM":        256,
+				"totalRAM":       tt.total / mebibyte,
+				"memUsedPercent": tt.percent,
+				"memAvailable":   tt.total - 268435456,
+			}
+
+			for key, value := range want {
+				if values[key] != value {
+					t.Errorf("%s = %v, want %v", key, values[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestOverridePoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []Point
+		overrides []Point
+		want      []Point
+	}{
+		{
+			name:      "replaced",
+			points:    []Point{{Key: "cpu", Value: 90}, {Key: "memUsed", Value: 8}},
+			overrides: []Point{{Key: "cpu", Value: 40}},
+			want:      []Point{{Key: "cpu", Value: 40}, {Key: "memUsed", Value: 8}},
+		},
+		{
+			name:      "added",
+			points:    []Point{{Key: "cpu", Value: 90}},
+			overrides: []Point{{Key: "memUsed", Value: 2}},
+			want:      []Point{{Key: "cpu", Value: 90}, {Key: "memUsed", Value: 2}},
+		},
+		{
+			name:   "no overrides",
+			points: []Point{{Key: "cpu", Value: 90}},
+			want:   []Point{{Key: "cpu", Value: 90}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := overridePoints(tt.points, tt.overrides)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("overridePoints = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("point %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCPUTotalOnly(t *testing.T) {
+	points := []Point{
+		{Key: "cpu", Value: 50}, {Key: "cpu0", Value: 90}, {Key: "cpu12", Value: 10},
+		{Key: "cpuUser", Value: 30}, {Key: "cpuIdle", Value: 50}, {Key: "memUsed", Value: 8}, {Key: "load1", Value: 1},
+	}
+
+	got := cpuTotalOnly(points)
+	want := []Point{{Key: "cpu", Value: 50}, {Key: "memUsed", Value: 8}, {Key: "load1", Value: 1}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cpuTotalOnly = %v, want %v", got, want)
+	}
+}
@@ -2,31 +2,54 @@
 	Author	:	Daniel Alexandre Neves de Carvalho
 	Date	:	15/06/2020
 	File	:	collector.go
-	Overview: 	Collector only provides two functions to get OS data.
-				CPU and RAM are collected from here.
+	Overview: 	Collector provides the collectors of this machine: OS (CPU
+				and RAM), disks, network interfaces and processes. Every
+				data source implements the Collector interface, see
+				registry.go.
 */
 
 package collector
 
 import (
-	"github.com/shirou/gopsutil/cpu"
-	"github.com/shirou/gopsutil/mem"
+	"strconv"
+	"strings"
 )
 
-// GetRAM - Collect RAM info from OS
-func GetRAM() []uint64 {
-	cpuData := make([]uint64, 2)
-	memStats, _ := mem.VirtualMemory()
+// Machine - Return the collectors of this machine, in the order they are shown:
+// OS (reading CPU and RAM as given by mode), DISK, NET and PROCESS (watching the
+// processes of watch). They read the machine when created, so only processes
+// that collect create them.
+func Machine(mode CgroupMode, watch []string) ([]Collector, error) {
+	osCollector, err := NewOS(mode)
 
-	cpuData[0] = memStats.Total / (1024 * 1024)
-	cpuData[1] = memStats.Used / (1024 * 1024)
+	if err != nil {
+		return nil, err
+	}
 
-	return cpuData
+	return []Collector{osCollector, NewDisk(), NewNetwork(), NewProcess(watch)}, nil
 }
 
-// GetCPU - Collect CPU info from OS
-func GetCPU() float64 {
-	cpuStats, _ := cpu.Percent(0, true)
+// MachineCode - Report whether code is taken by the collectors of this machine,
+// whatever its cores, disks, interfaces and processes are: variables of OS, usage
+// of any core (c0, c1, ...) and the codes labeled ones start with (e.g. du, rxb)
+func MachineCode(code string) bool {
+	variables := append(cpuVariables(0), memoryVariables()...)
+	variables = append(variables, hostVariables()...)
+	variables = append(variables, mountTemplates...)
+	variables = append(variables, deviceTemplates...)
+	variables = append(variables, processTemplates...)
 
-	return cpuStats[0]
+	for _, c := range netCounters {
+		variables = append(variables, c.Variable)
+	}
+
+	for _, v := range variables {
+		if v.Code == code {
+			return true
+		}
+	}
+
+	_, err := strconv.Atoi(strings.TrimPrefix(code, "c"))
+
+	return strings.HasPrefix(code, "c") && err == nil
 }
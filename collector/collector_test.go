@@ -0,0 +1,40 @@
+/*
+	Date	:	18/10/2026
+	File	:	collector_test.go
+	Overview: 	Tests of the codes taken by the collectors of this machine.
+*/
+
+package collector
+
+import "testing"
+
+func TestMachineCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"c", true},
+		{"c0", true},
+		{"c128", true},
+		{"cu", true},
+		{"r", true},
+		{"st", true},
+		{"up", true},
+		{"dt", true},
+		{"dr", true},
+		{"txd", true},
+		{"pr", true},
+		{"1", false},
+		{"cx", false},
+		{"temp", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := MachineCode(tt.code); got != tt.want {
+				t.Errorf("MachineCode(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+	Date	:	18/10/2026
+	File	:	cpu.go
+	Overview: 	CPU computes CPU usage from the difference between two
+				readings of the CPU times, for the whole machine and for
+				every core, plus the share of time spent in user, system,
+				iowait, steal and idle.
+*/
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/cpu"
+)
+
+// cpuBreakdown - CPU time fields stored as a percentage of the elapsed CPU time
+var cpuBreakdown = []struct {
+	Code  string
+	Key   string
+	Name  string
+	field func(cpu.TimesStat) float64
+}{
+	{Code: "cu", Key: "cpuUser", Name: "CPU user", field: func(t cpu.TimesStat) float64 { return t.User }},
+	{Code: "cs", Key: "cpuSystem", Name: "CPU system", field: func(t cpu.TimesStat) float64 { return t.System }},
+	{Code: "cw", Key: "cpuIowait", Name: "CPU iowait", field: func(t cpu.TimesStat) float64 { return t.Iowait }},
+	{Code: "ct", Key: "cpuSteal", Name: "CPU steal", field: func(t cpu.TimesStat) float64 { return t.Steal }},
+	{Code: "ci", Key: "cpuIdle", Name: "CPU idle", field: func(t cpu.TimesStat) float64 { return t.Idle }},
+}
+
+// cpuTimes type - previous reading of the CPU times, whole machine and per core
+type cpuTimes struct {
+	total cpu.TimesStat
+	cores []cpu.TimesStat
+}
+
+// readCPUTimes - Read CPU times of the whole machine and of every core
+func readCPUTimes(ctx context.Context) (cpuTimes, error) {
+	total, err := cpu.TimesWithContext(ctx, false)
+
+	if err != nil || len(total) == 0 {
+		return cpuTimes{}, fmt.Errorf("[Collector] - Error reading CPU times: %v", err)
+	}
+
+	cores, err := cpu.TimesWithContext(ctx, true)
+
+	if err != nil {
+		return cpuTimes{}, fmt.Errorf("[Collector] - Error reading CPU times per core: %v", err)
+	}
+
+	return cpuTimes{total: total[0], cores: cores}, nil
+}
+
+// cpuVariables - Return CPU variables: usage of the whole machine, of each one of
+// the cores (c0, c1, ...) and the time breakdown
+func cpuVariables(cores int) []Variable {
+	variables := []Variable{{Code: "c", Key: "cpu", Name: "CPU", Unit: "%"}}
+
+	for i := 0; i < cores; i++ {
+		variables = append(variables, Variable{Code: fmt.Sprintf("c%d", i), Key: fmt.Sprintf("cpu%d", i), Name: fmt.Sprintf("CPU %d", i), Unit: "%"})
+	}
+
+	for _, b := range cpuBreakdown {
+		variables = append(variables, Variable{Code: b.Code, Key: b.Key, Name: b.Name, Unit: "%"})
+	}
+
+	return variables
+}
+
+// cpuPoints - Return CPU points for the time elapsed between two readings. Values
+// that had no CPU time elapsed are left out.
+func cpuPoints(previous cpuTimes, current cpuTimes) []Point {
+	var points []Point
+
+	elapsed := current.total.Total() - previous.total.Total()
+
+	if elapsed > 0 {
+		points = append(points, Point{Key: "cpu", Value: busyPercent(previous.total, current.total)})
+
+		for _, b := range cpuBreakdown {
+			points = append(points, Point{Key: b.Key, Value: clampPercent((b.field(current.total) - b.field(previous.total)) / elapsed * 100)})
+		}
+	}
+
+	// Cores are matched by position, as they are listed by the OS
+	for i := 0; i < len(current.cores) && i < len(previous.cores); i++ {
+		if current.cores[i].Total() > previous.cores[i].Total() {
+			points = append(points, Point{Key: fmt.Sprintf("cpu%d", i), Value: busyPercent(previous.cores[i], current.cores[i])})
+		}
+	}
+
+	return points
+}
+
+// cpuTotalOnly - Return points without the usage of each core and the time
+// breakdown, left out when CPU usage is read from a cgroup
+func cpuTotalOnly(points []Point) []Point {
+	breakdown := make(map[string]bool)
+
+	for _, b := range cpuBreakdown {
+		breakdown[b.Key] = true
+	}
+
+	var kept []Point
+
+	for _, p := range points {
+		// Cores have keys like cpu0, cpu1, ...
+		_, err := strconv.Atoi(strings.TrimPrefix(p.Key, "cpu"))
+		core := strings.HasPrefix(p.Key, "cpu") && err == nil
+
+		if core || breakdown[p.Key] {
+			continue
+		}
+		kept = append(kept, p)
+	}
+
+	return kept
+}
+
+// busyPercent - Return the percentage of CPU time not idle between two readings.
+// Iowait counts as busy, as done by cpu.Percent, and is also given on its own.
+func busyPercent(previous cpu.TimesStat, current cpu.TimesStat) float64 {
+	elapsed := current.Total() - previous.Total()
+	idle := current.Idle - previous.Idle
+
+	return clampPercent((elapsed - idle) / elapsed * 100)
+}
+
+// clampPercent - Keep a percentage between 0 and 100, since CPU times of some
+// systems can go slightly backwards
+func clampPercent(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	if value > 100 {
+		return 100
+	}
+
+	return value
+}
@@ -0,0 +1,82 @@
+/*
+	Date	:	18/10/2026
+	File	:	cpu_test.go
+	Overview: 	Tests of the CPU usage computed between two readings of the
+				CPU times.
+*/
+
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shirou/gopsutil/cpu"
+)
+
+func TestCPUPoints(t *testing.T) {
+	// Two cores, the first one busy for 20 of its 50 seconds
+	previous := cpuTimes{
+		total: cpu.TimesStat{User: 10, System: 10, Idle: 80},
+		cores: []cpu.TimesStat{{User: 5, Idle: 45}, {User: 5, Idle: 45}},
+	}
+	current := cpuTimes{
+		total: cpu.TimesStat{User: 30, System: 20, Iowait: 10, Idle: 140},
+		cores: []cpu.TimesStat{{User: 25, Idle: 75}, {User: 5, Idle: 95}},
+	}
+
+	tests := []struct {
+		name     string
+		previous cpuTimes
+		current  cpuTimes
+		want     map[string]float64
+	}{
+		{
+			name:     "usage and breakdown",
+			previous: previous,
+			current:  current,
+			want: map[string]float64{
+				"cpu": 40, "cpuUser": 20, "cpuSystem": 10, "cpuIowait": 10, "cpuSteal": 0, "cpuIdle": 60,
+				"cpu0": 40, "cpu1": 0,
+			},
+		},
+		{
+			name:     "no time elapsed",
+			previous: previous,
+			current:  previous,
+			want:     map[string]float64{},
+		},
+		{
+			name:     "counters going backwards",
+			previous: current,
+			current:  previous,
+			want:     map[string]float64{},
+		},
+		{
+			name:     "idle going backwards",
+			previous: cpuTimes{total: cpu.TimesStat{User: 10, Idle: 90}},
+			current:  cpuTimes{total: cpu.TimesStat{User: 120, Idle: 80}},
+			want:     map[string]float64{"cpu": 100, "cpuUser": 100, "cpuSystem": 0, "cpuIowait": 0, "cpuSteal": 0, "cpuIdle": 0},
+		},
+		{
+			name:     "core gone",
+			previous: previous,
+			current:  cpuTimes{total: previous.total, cores: current.cores[:1]},
+			want:     map[string]float64{"cpu0": 40},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]float64)
+
+			for _, p := range cpuPoints(tt.previous, tt.current) {
+				got[p.Key] = p.Value
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cpuPoints = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+/*
+	Date	:	18/10/2026
+	File	:	disk.go
+	Overview: 	Disk is the collector of filesystem usage of every mount and
+				IO rates of every block device, stored in DISK table. Rates
+				are computed from the difference between two readings of the
+				device counters, so the previous reading is kept.
+*/
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+)
+
+// mountTemplates - variables of every mount, labeled with its path (e.g. du:/)
+var mountTemplates = []Variable{
+	{Code: "dt", Key: "total", Name: "total", Unit: "B"},
+	{Code: "du", Key: "used", Name: "used", Unit: "B"},
+	{Code: "dp", Key: "usedPercent", Name: "used", Unit: "%"},
+	{Code: "di", Key: "inodesTotal", Name: "inodes"},
+	{Code: "dn", Key: "inodesUsed", Name: "inodes used"},
+}
+
+// deviceTemplates - variables of every block device, labeled with its name (e.g. dr:sda)
+var deviceTemplates = []Variable{
+	{Code: "dr", Key: "readBytes", Name: "read", Unit: "B/s"},
+	{Code: "dw", Key: "writeBytes", Name: "write", Unit: "B/s"},
+	{Code: "do", Key: "iops", Name: "IOPS"},
+	{Code: "da", Key: "await", Name: "await", Unit: "ms"},
+}
+
+// Disk type - collector of mounts usage and devices IO rates
+type Disk struct {
+	mutex     sync.Mutex
+	mounts    []string
+	devices   []string
+	previous  map[string]disk.IOCountersStat
+	readAt    time.Time
+	variables []Variable
+}
+
+// NewDisk - Return a Disk collector for the mounts and devices found now, with
+// IO counters already read so the first Collect gives real rates
+func NewDisk() *Disk {
+	d := &Disk{}
+
+	partitions, _ := disk.Partitions(false)
+
+	for _, p := range partitions {
+		d.mounts = append(d.mounts, p.Mountpoint)
+	}
+
+	d.previous, _ = disk.IOCounters()
+	d.readAt = time.Now()
+
+	for name := range d.previous {
+		// Loop and RAM devices are not real disks
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+		d.devices = append(d.devices, name)
+	}
+
+	sort.Strings(d.mounts)
+	sort.Strings(d.devices)
+
+	for _, m := range d.mounts {
+		for _, t := range mountTemplates {
+			d.variables = append(d.variables, labeledVariable(t, m))
+		}
+	}
+
+	for _, dev := range d.devices {
+		for _, t := range deviceTemplates {
+			d.variables = append(d.variables, labeledVariable(t, dev))
+		}
+	}
+
+	return d
+}
+
+// Name - disk entries are stored in DISK table
+func (d *Disk) Name() string {
+	return "DISK"
+}
+
+// Variables - usage of every mount and IO rates of every device, with codes like
+// du:/ (used bytes of /) or dr:sda (bytes read per second from sda)
+func (d *Disk) Variables() []Variable {
+	return d.variables
+}
+
+// Resolve - Return the variable of any mount or device, e.g. du:/mnt/sd
+func (d *Disk) Resolve(code string) (Variable, bool) {
+	return resolveLabeled(code, append(mountTemplates, deviceTemplates...))
+}
+
+// Collect - Read usage of every mount and IO rates since the previous Collect.
+// Mounts that cant be read are left out.
+func (d *Disk) Collect(ctx context.Context) ([]Point, error) {
+	var points []Point
+
+	for _, m := range d.mounts {
+		usage, err := disk.UsageWithContext(ctx, m)
+
+		if err != nil {
+			continue
+		}
+
+		points = append(points,
+			Point{Key: labeledVariable(mountTemplates[0], m).Key, Value: float64(usage.Total)},
+			Point{Key: labeledVariable(mountTemplates[1], m).Key, Value: float64(usage.Used)},
+			Point{Key: labeledVariable(mountTemplates[2], m).Key, Value: usage.UsedPercent},
+			Point{Key: labeledVariable(mountTemplates[3], m).Key, Value: float64(usage.InodesTotal)},
+			Point{Key: labeledVariable(mountTemplates[4], m).Key, Value: float64(usage.InodesUsed)},
+		)
+	}
+
+	current, err := disk.IOCountersWithContext(ctx, d.devices...)
+
+	if err != nil {
+		return nil, fmt.Errorf("[Collector] - Error reading disk IO counters: %v", err)
+	}
+
+	now := time.Now()
+
+	d.mutex.Lock()
+	elapsed := now.Sub(d.readAt).Seconds()
+
+	points = append(points, devicePoints(d.devices, d.previous, current, elapsed)...)
+
+	d.previous = current
+	d.readAt = now
+	d.mutex.Unlock()
+
+	return points, nil
+}
+
+// devicePoints - Return IO rates of devices between two readings elapsed seconds
+// apart. Devices missing from either reading, gone or just back, give rates from
+// the next reading on, as their counters may have started over.
+func devicePoints(devices []string, previous map[string]disk.IOCountersStat, current map[string]disk.IOCountersStat, elapsed float64) []Point {
+	var points []Point
+
+	for _, dev := range devices {
+		before, seen := previous[dev]
+		now, ok := current[dev]
+
+		if seen && ok {
+			points = append(points, ioPoints(dev, before, now, elapsed, counterBits)...)
+		}
+	}
+
+	return points
+}
+
+// ioPoints - Return IO rates of device dev between two readings elapsed seconds
+// apart, from counters width bits wide. Counters that went backwards (device
+// reset) give no points, see counterDelta.
+func ioPoints(dev string, previous disk.IOCountersStat, current disk.IOCountersStat, elapsed float64, width int) []Point {
+	if elapsed <= 0 {
+		return nil
+	}
+
+	// Bytes read and written, IOs read and written and time spent on them (ms)
+	counters := [][2]uint64{
+		{previous.ReadBytes, current.ReadBytes},
+		{previous.WriteBytes, current.WriteBytes},
+		{previous.ReadCount, current.ReadCount},
+		{previous.WriteCount, current.WriteCount},
+		{previous.ReadTime, current.ReadTime},
+		{previous.WriteTime, current.WriteTime},
+	}
+
+	var deltas []float64
+
+	for _, c := range counters {
+		delta, ok := counterDelta(c[0], c[1], width)
+
+		if !ok {
+			return nil
+		}
+		deltas = append(deltas, delta)
+	}
+
+	ops := deltas[2] + deltas[3]
+
+	// Average time of each IO, zero when there were none
+	await := 0.0
+
+	if ops > 0 {
+		await = (deltas[4] + deltas[5]) / ops
+	}
+
+	return []Point{
+		{Key: labeledVariable(deviceTemplates[0], dev).Key, Value: deltas[0] / elapsed},
+		{Key: labeledVariable(deviceTemplates[1], dev).Key, Value: deltas[1] / elapsed},
+		{Key: labeledVariable(deviceTemplates[2], dev).Key, Value: ops / elapsed},
+		{Key: labeledVariable(deviceTemplates[3], dev).Key, Value: await},
+	}
+}
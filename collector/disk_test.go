@@ -0,0 +1,148 @@
+/*
+	Date	:	18/10/2026
+	File	:	disk_test.go
+	Overview: 	Tests of the IO rates computed from block device counters.
+*/
+
+package collector
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/shirou/gopsutil/disk"
+)
+
+// pointValues - Return the values of points by key
+func pointValues(points []Point) map[string]float64 {
+	values := make(map[string]float64)
+
+	for _, p := range points {
+		values[p.Key] = p.Value
+	}
+
+	return values
+}
+
+func TestIOPoints(t *testing.T) {
+	previous := disk.IOCountersStat{ReadBytes: 1000, WriteBytes: 2000, ReadCount: 10, WriteCount: 20, ReadTime: 100, WriteTime: 200}
+
+	// Readings start from previous unless they give their own
+	tests := []struct {
+		name     string
+		previous disk.IOCountersStat
+		current  disk.IOCountersStat
+		elapsed  float64
+		width    int
+		want     map[string]float64
+	}{
+		{
+			name:    "rates",
+			current: disk.IOCountersStat{ReadBytes: 3000, WriteBytes: 6000, ReadCount: 20, WriteCount: 30, ReadTime: 150, WriteTime: 350},
+			elapsed: 2,
+			width:   64,
+			want:    map[string]float64{"readBytes:sda": 1000, "writeBytes:sda": 2000, "iops:sda": 10, "await:sda": 10},
+		},
+		{
+			name:    "no IO",
+			current: previous,
+			elapsed: 2,
+			width:   64,
+			want:    map[string]float64{"readBytes:sda": 0, "writeBytes:sda": 0, "iops:sda": 0, "await:sda": 0},
+		},
+		{
+			name:    "no time elapsed",
+			current: disk.IOCountersStat{ReadBytes: 3000, WriteBytes: 6000, ReadCount: 20, WriteCount: 30, ReadTime: 150, WriteTime: 350},
+			elapsed: 0,
+			width:   64,
+			want:    map[string]float64{},
+		},
+		{
+			name:    "bytes reset",
+			current: disk.IOCountersStat{ReadBytes: 10, WriteBytes: 6000, ReadCount: 20, WriteCount: 30, ReadTime: 150, WriteTime: 350},
+			elapsed: 2,
+			width:   64,
+			want:    map[string]float64{},
+		},
+		{
+			name:    "IO time reset",
+			current: disk.IOCountersStat{ReadBytes: 3000, WriteBytes: 6000, ReadCount: 20, WriteCount: 30, ReadTime: 150, WriteTime: 5},
+			elapsed: 2,
+			width:   64,
+			want:    map[string]float64{},
+		},
+		{
+			name:     "32 bit wrap",
+			previous: disk.IOCountersStat{ReadBytes: math.MaxUint32 - 499},
+			current:  disk.IOCountersStat{ReadBytes: 500},
+			elapsed:  1,
+			width:    32,
+			want:     map[string]float64{"readBytes:sda": 1000, "writeBytes:sda": 0, "iops:sda": 0, "await:sda": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := previous
+
+			if tt.previous != (disk.IOCountersStat{}) {
+				before = tt.previous
+			}
+
+			got := pointValues(ioPoints("sda", before, tt.current, tt.elapsed, tt.width))
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ioPoints = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDevicePoints(t *testing.T) {
+	counters := func(readBytes uint64) disk.IOCountersStat {
+		return disk.IOCountersStat{ReadBytes: readBytes}
+	}
+
+	tests := []struct {
+		name     string
+		previous map[string]disk.IOCountersStat
+		current  map[string]disk.IOCountersStat
+		want     map[string]float64
+	}{
+		{
+			name:     "read twice",
+			previous: map[string]disk.IOCountersStat{"sda": counters(100)},
+			current:  map[string]disk.IOCountersStat{"sda": counters(300)},
+			want:     map[string]float64{"readBytes:sda": 200, "writeBytes:sda": 0, "iops:sda": 0, "await:sda": 0},
+		},
+		{
+			name:     "gone",
+			previous: map[string]disk.IOCountersStat{"sda": counters(100)},
+			current:  map[string]disk.IOCountersStat{},
+			want:     map[string]float64{},
+		},
+		{
+			name:     "back with counters of its whole life",
+			previous: map[string]disk.IOCountersStat{},
+			current:  map[string]disk.IOCountersStat{"sda": counters(5000000)},
+			want:     map[string]float64{},
+		},
+		{
+			name:     "not watched",
+			previous: map[string]disk.IOCountersStat{"sdb": counters(100)},
+			current:  map[string]disk.IOCountersStat{"sdb": counters(300)},
+			want:     map[string]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pointValues(devicePoints([]string{"sda"}, tt.previous, tt.current, 1))
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("devicePoints = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
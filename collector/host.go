@@ -0,0 +1,84 @@
+/*
+	Date	:	18/10/2026
+	File	:	host.go
+	Overview: 	Host reads load averages, uptime and boot time for the OS
+				collector, and the metadata of the machine (hostname, OS,
+				kernel, CPU model and cores) kept once per session.
+*/
+
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/host"
+	"github.com/shirou/gopsutil/load"
+)
+
+// HostInfo type - metadata of the machine where data is collected
+type HostInfo struct {
+	Hostname string
+	OS       string
+	Platform string
+	Kernel   string
+	CPUModel string
+	Cores    int
+}
+
+// GetHostInfo - Collect metadata of the machine. Fields that cant be read are
+// left empty.
+func GetHostInfo() HostInfo {
+	var info HostInfo
+
+	if hostStats, err := host.Info(); err == nil {
+		info.Hostname = hostStats.Hostname
+		info.OS = hostStats.OS
+		info.Platform = fmt.Sprintf("%s %s", hostStats.Platform, hostStats.PlatformVersion)
+		info.Kernel = hostStats.KernelVersion
+	}
+
+	if cpuStats, err := cpu.Info(); err == nil && len(cpuStats) > 0 {
+		info.CPUModel = cpuStats[0].ModelName
+	}
+
+	info.Cores, _ = cpu.Counts(true)
+
+	return info
+}
+
+// hostVariables - Return load averages, uptime and boot time variables
+func hostVariables() []Variable {
+	return []Variable{
+		{Code: "l1", Key: "load1", Name: "Load 1m"},
+		{Code: "l5", Key: "load5", Name: "Load 5m"},
+		{Code: "l15", Key: "load15", Name: "Load 15m"},
+		{Code: "up", Key: "uptime", Name: "Uptime", Unit: "s"},
+		{Code: "bt", Key: "bootTime", Name: "Boot time", Unit: "s"},
+	}
+}
+
+// hostPoints - Read load averages, uptime and boot time (Unix seconds). Values
+// that cant be read are left out.
+func hostPoints(ctx context.Context) []Point {
+	var points []Point
+
+	if loadStats, err := load.AvgWithContext(ctx); err == nil {
+		points = append(points,
+			Point{Key: "load1", Value: loadStats.Load1},
+			Point{Key: "load5", Value: loadStats.Load5},
+			Point{Key: "load15", Value: loadStats.Load15},
+		)
+	}
+
+	if uptime, err := host.UptimeWithContext(ctx); err == nil {
+		points = append(points, Point{Key: "uptime", Value: float64(uptime)})
+	}
+
+	if bootTime, err := host.BootTimeWithContext(ctx); err == nil {
+		points = append(points, Point{Key: "bootTime", Value: float64(bootTime)})
+	}
+
+	return points
+}
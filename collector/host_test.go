@@ -0,0 +1,92 @@
+/*
+	Date	:	18/10/2026
+	File	:	host_test.go
+	Overview: 	Tests of the load, uptime and boot time read for the OS
+				collector and of the metadata of the machine.
+*/
+
+package collector
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHostPoints(t *testing.T) {
+	values := make(map[string]float64)
+
+	for _, p := range hostPoints(context.Background()) {
+		values[p.Key] = p.Value
+	}
+
+	// Every point is a host variable
+	keys := make(map[string]bool)
+
+	for _, v := range hostVariables() {
+		keys[v.Key] = true
+	}
+
+	for key := range values {
+		if !keys[key] {
+			t.Errorf("point %s is not a host variable", key)
+		}
+	}
+
+	now := float64(time.Now().Unix())
+
+	tests := []struct {
+		key   string
+		valid func(value float64) bool
+	}{
+		{"load1", func(value float64) bool { return value >= 0 }},
+		{"load5", func(value float64) bool { return value >= 0 }},
+		{"load15", func(value float64) bool { return value >= 0 }},
+		{"uptime", func(value float64) bool { return value > 0 && value < now }},
+		// Machine booted uptime seconds ago, give or take the time to read both
+		{"bootTime", func(value float64) bool { return math.Abs(value+values["uptime"]-now) <= 5 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, ok := values[tt.key]
+
+			if !ok {
+				t.Fatalf("no %s point", tt.key)
+			}
+			if !tt.valid(value) {
+				t.Errorf("%s = %v", tt.key, value)
+			}
+		})
+	}
+}
+
+func TestGetHostInfo(t *testing.T) {
+	info := GetHostInfo()
+	hostname, err := os.Hostname()
+
+	if err != nil {
+		t.Fatalf("hostname: %v", err)
+	}
+
+	tests := []struct {
+		field string
+		got   interface{}
+		valid bool
+	}{
+		{"Hostname", info.Hostname, info.Hostname == hostname},
+		{"OS", info.OS, info.OS != ""},
+		{"Kernel", info.Kernel, info.Kernel != ""},
+		{"Cores", info.Cores, info.Cores > 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if !tt.valid {
+				t.Errorf("%s = %v", tt.field, tt.got)
+			}
+		})
+	}
+}
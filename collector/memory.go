@@ -0,0 +1,73 @@
+/*
+	Date	:	18/10/2026
+	File	:	memory.go
+	Overview: 	Memory reads RAM and swap usage for the OS collector. Used
+				RAM alone gives false alarms on Linux, where the page cache
+				fills free memory, so available, cached and buffers memory
+				are stored too, all of them in bytes.
+*/
+
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shirou/gopsutil/mem"
+)
+
+// mebibyte - bytes in a MiB, unit of the legacy totalRAM and usedRAM keys
+const mebibyte = 1024 * 1024
+
+// memoryVariables - Return memory variables, all of them in bytes but the legacy
+// usedRAM key. Older versions stored usedRAM and totalRAM in MiB (unit "Mb") and
+// they are still written, so variable r reads the same through old and new
+// entries. memUsed and memTotal hold the same values in bytes.
+func memoryVariables() []Variable {
+	return []Variable{
+		{Code: "r", Key: "usedRAM", Name: "RAM", Unit: "Mb"},
+		{Code: "ru", Key: "memUsed", Name: "RAM used", Unit: "B"},
+		{Code: "rt", Key: "memTotal", Name: "RAM total", Unit: "B"},
+		{Code: "rp", Key: "memUsedPercent", Name: "RAM used", Unit: "%"},
+		{Code: "ra", Key: "memAvailable", Name: "RAM available", Unit: "B"},
+		{Code: "rf", Key: "memFree", Name: "RAM free", Unit: "B"},
+		{Code: "rc", Key: "memCached", Name: "RAM cached", Unit: "B"},
+		{Code: "rb", Key: "memBuffers", Name: "RAM buffers", Unit: "B"},
+		{Code: "st", Key: "swapTotal", Name: "Swap total", Unit: "B"},
+		{Code: "su", Key: "swapUsed", Name: "Swap used", Unit: "B"},
+	}
+}
+
+// memoryPoints - Read RAM and swap usage, and used and total RAM in MiB under the
+// legacy keys
+func memoryPoints(ctx context.Context) ([]Point, error) {
+	memStats, err := mem.VirtualMemoryWithContext(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("[Collector] - Error reading memory usage: %v", err)
+	}
+
+	points := []Point{
+		{Key: "totalRAM", Value: float64(memStats.Total) / mebibyte},
+		{Key: "usedRAM", Value: float64(memStats.Used) / mebibyte},
+		{Key: "memUsed", Value: float64(memStats.Used)},
+		{Key: "memTotal", Value: float64(memStats.Total)},
+		{Key: "memUsedPercent", Value: memStats.UsedPercent},
+		{Key: "memAvailable", Value: float64(memStats.Available)},
+		{Key: "memFree", Value: float64(memStats.Free)},
+		{Key: "memCached", Value: float64(memStats.Cached)},
+		{Key: "memBuffers", Value: float64(memStats.Buffers)},
+	}
+
+	swapStats, err := mem.SwapMemoryWithContext(ctx)
+
+	// Machines without swap still have RAM values
+	if err == nil {
+		points = append(points,
+			Point{Key: "swapTotal", Value: float64(swapStats.Total)},
+			Point{Key: "swapUsed", Value: float64(swapStats.Used)},
+		)
+	}
+
+	return points, nil
+}
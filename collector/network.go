@@ -0,0 +1,194 @@
+/*
+	Date	:	18/10/2026
+	File	:	network.go
+	Overview: 	Network is the collector of throughput of every network
+				interface, stored in NET table. Received and sent bytes,
+				packets, errors and drops are turned into rates per second
+				from the difference between two readings of the counters.
+*/
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/net"
+)
+
+// netCounters - counters of an interface turned into rates, with the variable
+// they give labeled with the interface name (e.g. rxb:eth0)
+var netCounters = []struct {
+	Variable
+	counter func(net.IOCountersStat) uint64
+}{
+	{Variable{Code: "rxb", Key: "rxBytes", Name: "rx", Unit: "B/s"}, func(c net.IOCountersStat) uint64 { return c.BytesRecv }},
+	{Variable{Code: "txb", Key: "txBytes", Name: "tx", Unit: "B/s"}, func(c net.IOCountersStat) uint64 { return c.BytesSent }},
+	{Variable{Code: "rxp", Key: "rxPackets", Name: "rx packets", Unit: "/s"}, func(c net.IOCountersStat) uint64 { return c.PacketsRecv }},
+	{Variable{Code: "txp", Key: "txPackets", Name: "tx packets", Unit: "/s"}, func(c net.IOCountersStat) uint64 { return c.PacketsSent }},
+	{Variable{Code: "rxe", Key: "rxErrors", Name: "rx errors", Unit: "/s"}, func(c net.IOCountersStat) uint64 { return c.Errin }},
+	{Variable{Code: "txe", Key: "txErrors", Name: "tx errors", Unit: "/s"}, func(c net.IOCountersStat) uint64 { return c.Errout }},
+	{Variable{Code: "rxd", Key: "rxDrops", Name: "rx drops", Unit: "/s"}, func(c net.IOCountersStat) uint64 { return c.Dropin }},
+	{Variable{Code: "txd", Key: "txDrops", Name: "tx drops", Unit: "/s"}, func(c net.IOCountersStat) uint64 { return c.Dropout }},
+}
+
+// counterBits - bits of the interface counters. The kernel keeps them as unsigned
+// long, so they are 32 bits wide on 32 bit systems.
+const counterBits = bits.UintSize
+
+// Network type - collector of rates of every interface. Interfaces seen since it
+// was created are kept as variables, even after they disappear. Others are found
+// by Resolve, so their stored points can still be queried.
+type Network struct {
+	mutex      sync.Mutex
+	interfaces []string
+	previous   map[string]net.IOCountersStat
+	readAt     time.Time
+}
+
+// NewNetwork - Return a Network collector with counters already read, so the
+// first Collect gives real rates
+func NewNetwork() *Network {
+	n := &Network{previous: make(map[string]net.IOCountersStat), readAt: time.Now()}
+
+	counters, _ := net.IOCounters(true)
+
+	for _, c := range counters {
+		n.previous[c.Name] = c
+		n.interfaces = append(n.interfaces, c.Name)
+	}
+
+	sort.Strings(n.interfaces)
+
+	return n
+}
+
+// Name - network entries are stored in NET table
+func (n *Network) Name() string {
+	return "NET"
+}
+
+// Variables - rates of every interface seen, with codes like rxb:eth0 (bytes
+// received per second by eth0)
+func (n *Network) Variables() []Variable {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	var variables []Variable
+
+	for _, name := range n.interfaces {
+		for _, c := range netCounters {
+			variables = append(variables, labeledVariable(c.Variable, name))
+		}
+	}
+
+	return variables
+}
+
+// Resolve - Return the variable of any interface, e.g. rxb:wlan0
+func (n *Network) Resolve(code string) (Variable, bool) {
+	var templates []Variable
+
+	for _, c := range netCounters {
+		templates = append(templates, c.Variable)
+	}
+
+	return resolveLabeled(code, templates)
+}
+
+// Collect - Read rates of every interface since the previous Collect. New
+// interfaces give rates from the next Collect on.
+func (n *Network) Collect(ctx context.Context) ([]Point, error) {
+	var points []Point
+
+	counters, err := net.IOCountersWithContext(ctx, true)
+
+	if err != nil {
+		return nil, fmt.Errorf("[Collector] - Error reading network counters: %v", err)
+	}
+
+	now := time.Now()
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	elapsed := now.Sub(n.readAt).Seconds()
+	current := make(map[string]net.IOCountersStat)
+
+	for _, c := range counters {
+		current[c.Name] = c
+
+		previous, seen := n.previous[c.Name]
+
+		// Interface just appeared, keep it as a variable from now on
+		if !seen {
+			if !containsString(n.interfaces, c.Name) {
+				n.interfaces = append(n.interfaces, c.Name)
+				sort.Strings(n.interfaces)
+			}
+			continue
+		}
+
+		points = append(points, netPoints(c.Name, previous, c, elapsed, counterBits)...)
+	}
+
+	// Interfaces that disappeared are left out of previous, so they start over
+	n.previous = current
+	n.readAt = now
+
+	return points, nil
+}
+
+// netPoints - Return rates of interface name between two readings elapsed seconds
+// apart, from counters width bits wide. Counters that went backwards (interface
+// reset) give no points, see counterDelta.
+func netPoints(name string, previous net.IOCountersStat, current net.IOCountersStat, elapsed float64, width int) []Point {
+	if elapsed <= 0 {
+		return nil
+	}
+
+	var points []Point
+
+	for _, counter := range netCounters {
+		delta, ok := counterDelta(counter.counter(previous), counter.counter(current), width)
+
+		if !ok {
+			return nil
+		}
+
+		points = append(points, Point{Key: labeledVariable(counter.Variable, name).Key, Value: delta / elapsed})
+	}
+
+	return points
+}
+
+// counterDelta - Return how much a counter width bits wide grew between two
+// readings. Only 32 bit counters wrap around in practice, so a 64 bit counter
+// that went backwards was reset and gives no delta.
+func counterDelta(previous uint64, current uint64, width int) (float64, bool) {
+	if current >= previous {
+		return float64(current - previous), true
+	}
+
+	if width == 32 && previous <= math.MaxUint32 {
+		return float64(math.MaxUint32 - previous + current + 1), true
+	}
+
+	return 0, false
+}
+
+// containsString - Return true when list has value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
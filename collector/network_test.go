@@ -0,0 +1,85 @@
+/*
+	Date	:	18/10/2026
+	File	:	network_test.go
+	Overview: 	Tests of the rates computed from network interface counters.
+*/
+
+package collector
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shirou/gopsutil/net"
+)
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous uint64
+		current  uint64
+		width    int
+		want     float64
+		wantOK   bool
+	}{
+		{"grew", 100, 250, 64, 150, true},
+		{"unchanged", 100, 100, 64, 0, true},
+		{"64 bit reset", 5000, 10, 64, 0, false},
+		{"32 bit wrap", math.MaxUint32 - 9, 20, 32, 30, true},
+		{"32 bit wrap of a 64 bit counter", math.MaxUint32 - 9, 20, 64, 0, false},
+		{"32 bit counter above 32 bits", math.MaxUint32 + 10, 20, 32, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := counterDelta(tt.previous, tt.current, tt.width)
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("counterDelta(%d, %d, %d) = %v, %v, want %v, %v", tt.previous, tt.current, tt.width, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNetPoints(t *testing.T) {
+	previous := net.IOCountersStat{Name: "eth0", BytesRecv: 1000, BytesSent: 2000, PacketsRecv: 10, PacketsSent: 20}
+
+	tests := []struct {
+		name    string
+		current net.IOCountersStat
+		elapsed float64
+		want    map[string]float64
+	}{
+		{
+			name:    "rates per second",
+			current: net.IOCountersStat{Name: "eth0", BytesRecv: 3000, BytesSent: 2500, PacketsRecv: 14, PacketsSent: 20, Errin: 2},
+			elapsed: 2,
+			want:    map[string]float64{"rxBytes:eth0": 1000, "txBytes:eth0": 250, "rxPackets:eth0": 2, "txPackets:eth0": 0, "rxErrors:eth0": 1, "txErrors:eth0": 0, "rxDrops:eth0": 0, "txDrops:eth0": 0},
+		},
+		{
+			name:    "interface reset",
+			current: net.IOCountersStat{Name: "eth0", BytesRecv: 50, BytesSent: 2500, PacketsRecv: 14, PacketsSent: 20},
+			elapsed: 1,
+		},
+		{
+			name:    "no time elapsed",
+			current: previous,
+			elapsed: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := netPoints("eth0", previous, tt.current, tt.elapsed, 64)
+
+			if len(points) != len(tt.want) {
+				t.Fatalf("got %d points, want %d", len(points), len(tt.want))
+			}
+			for _, p := range points {
+				if want, ok := tt.want[p.Key]; !ok || p.Value != want {
+					t.Errorf("%s = %v, want %v", p.Key, p.Value, want)
+				}
+			}
+		})
+	}
+}
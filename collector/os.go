@@ -0,0 +1,137 @@
+/*
+	Date	:	18/10/2026
+	File	:	os.go
+	Overview: 	OS is the collector of CPU and memory usage, load and uptime
+				of the machine, stored in OS table. It is registered by default.
+*/
+
+package collector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shirou/gopsutil/cpu"
+)
+
+// OS type - collector of CPU and memory usage. CPU usage is the time elapsed
+// since the previous Collect, so OS keeps the previous CPU times. With a cgroup
+// CPU and RAM are relative to its quota (see cgroup.go).
+type OS struct {
+	mutex     sync.Mutex
+	cores     int
+	previous  cpuTimes
+	cgroup    *cgroup
+	variables []Variable
+}
+
+// NewOS - Return an OS collector reading CPU and RAM from the cgroup of this
+// process as given by mode, with CPU times already read so the first Collect
+// gives a real usage
+func NewOS(mode CgroupMode) (*OS, error) {
+	var err error
+
+	o := &OS{}
+
+	o.cgroup, err = openCgroup(mode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	o.previous, _ = readCPUTimes(context.Background())
+	o.cores, _ = cpu.Counts(true)
+
+	// Cores read are the ones that can be measured
+	if len(o.previous.cores) > 0 {
+		o.cores = len(o.previous.cores)
+	}
+
+	o.variables = cpuVariables(o.cores)
+
+	// Cgroups only give the whole CPU usage, see cgroup.points
+	if o.cgroup != nil {
+		o.variables = o.variables[:1]
+	}
+
+	o.variables = append(o.variables, memoryVariables()...)
+	o.variables = append(o.variables, hostVariables()...)
+
+	return o, nil
+}
+
+// Cgroup - Return the cgroup version read (1 or 2), zero when host numbers are read
+func (o *OS) Cgroup() int {
+	if o.cgroup == nil {
+		return 0
+	}
+
+	return o.cgroup.version
+}
+
+// Name - OS entries are stored in OS table
+func (o *OS) Name() string {
+	return "OS"
+}
+
+// Variables - CPU usage (whole machine, per core and breakdown), memory usage,
+// load averages, uptime and boot time
+func (o *OS) Variables() []Variable {
+	return o.variables
+}
+
+// Collect - Read CPU usage since the previous Collect, memory usage, load
+// averages, uptime and boot time
+func (o *OS) Collect(ctx context.Context) ([]Point, error) {
+	current, err := readCPUTimes(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	o.mutex.Lock()
+	points := cpuPoints(o.previous, current)
+	o.previous = current
+	o.mutex.Unlock()
+
+	memory, err := memoryPoints(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	points = append(points, memory...)
+
+	// Inside a cgroup, CPU and RAM relative to its quota replace host numbers
+	if o.cgroup != nil {
+		points, err = o.cgroupPoints(points)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return append(points, hostPoints(ctx)...), nil
+}
+
+// cgroupPoints - Replace CPU and RAM of host points with the ones of the cgroup,
+// leaving out host CPU numbers the cgroup has no match for
+func (o *OS) cgroupPoints(points []Point) ([]Point, error) {
+	hostTotal := 0.0
+
+	for _, p := range points {
+		if p.Key == "memTotal" {
+			hostTotal = p.Value
+		}
+	}
+
+	o.mutex.Lock()
+	overrides, err := o.cgroup.points(hostTotal)
+	o.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return overridePoints(cpuTotalOnly(points), overrides), nil
+}
@@ -0,0 +1,193 @@
+/*
+	Date	:	18/10/2026
+	File	:	process.go
+	Overview: 	Process is the collector of resources used by a watch list of
+				processes, stored in PROCESS table. Each process is given by
+				its name or by the path of its pidfile, and a change of PID
+				counts as a restart.
+*/
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// processTemplates - variables of every watched process, labeled with its name
+// or pidfile (e.g. pc:gatewayd)
+var processTemplates = []Variable{
+	{Code: "pc", Key: "cpu", Name: "CPU", Unit: "%"},
+	{Code: "pm", Key: "rss", Name: "RSS", Unit: "B"},
+	{Code: "pf", Key: "fds", Name: "FDs"},
+	{Code: "ph", Key: "threads", Name: "threads"},
+	{Code: "pr", Key: "restarts", Name: "restarts"},
+}
+
+// watched type - state of a watched process between two readings
+type watched struct {
+	pid      int32
+	cpuTime  float64
+	readAt   time.Time
+	restarts int
+}
+
+// Process type - collector of resources used by watched processes
+type Process struct {
+	mutex   sync.Mutex
+	targets []string
+	state   map[string]*watched
+}
+
+// NewProcess - Return a Process collector watching targets, each one a process
+// name (e.g. gatewayd) or the path of a pidfile (e.g. /run/gatewayd.pid)
+func NewProcess(targets []string) *Process {
+	p := &Process{state: make(map[string]*watched)}
+
+	for _, t := range targets {
+		t = strings.TrimSpace(t)
+
+		if t != "" {
+			p.targets = append(p.targets, t)
+			p.state[t] = &watched{}
+		}
+	}
+
+	return p
+}
+
+// Name - process entries are stored in PROCESS table
+func (p *Process) Name() string {
+	return "PROCESS"
+}
+
+// Variables - resources of every watched process, with codes like pc:gatewayd
+// (CPU usage of gatewayd)
+func (p *Process) Variables() []Variable {
+	var variables []Variable
+
+	for _, t := range p.targets {
+		for _, template := range processTemplates {
+			variables = append(variables, labeledVariable(template, t))
+		}
+	}
+
+	return variables
+}
+
+// Resolve - Return the variable of any process, watched or not by this process
+func (p *Process) Resolve(code string) (Variable, bool) {
+	return resolveLabeled(code, processTemplates)
+}
+
+// Collect - Read resources of every watched process. Processes not running
+// only give their number of restarts.
+func (p *Process) Collect(ctx context.Context) ([]Point, error) {
+	var points []Point
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, t := range p.targets {
+		state := p.state[t]
+		proc, err := findProcess(ctx, t)
+
+		if err == nil {
+			points = append(points, p.readProcess(ctx, t, state, proc)...)
+		}
+
+		points = append(points, Point{Key: labeledVariable(processTemplates[4], t).Key, Value: float64(state.restarts)})
+	}
+
+	return points, nil
+}
+
+// readProcess - Return CPU usage since the previous reading, RSS, open files and
+// threads of proc, updating the state of target t
+func (p *Process) readProcess(ctx context.Context, t string, state *watched, proc *process.Process) []Point {
+	var points []Point
+
+	now := time.Now()
+
+	// A new PID is the same process started again
+	if state.pid != 0 && state.pid != proc.Pid {
+		state.restarts++
+	}
+
+	if times, err := proc.TimesWithContext(ctx); err == nil {
+		cpuTime := times.User + times.System
+
+		// CPU usage needs a previous reading of the same PID
+		if state.pid == proc.Pid && now.After(state.readAt) {
+			points = append(points, Point{Key: labeledVariable(processTemplates[0], t).Key, Value: (cpuTime - state.cpuTime) / now.Sub(state.readAt).Seconds() * 100})
+		}
+
+		state.cpuTime = cpuTime
+	}
+
+	state.pid = proc.Pid
+	state.readAt = now
+
+	if memory, err := proc.MemoryInfoWithContext(ctx); err == nil {
+		points = append(points, Point{Key: labeledVariable(processTemplates[1], t).Key, Value: float64(memory.RSS)})
+	}
+
+	if fds, err := proc.NumFDsWithContext(ctx); err == nil {
+		points = append(points, Point{Key: labeledVariable(processTemplates[2], t).Key, Value: float64(fds)})
+	}
+
+	if threads, err := proc.NumThreadsWithContext(ctx); err == nil {
+		points = append(points, Point{Key: labeledVariable(processTemplates[3], t).Key, Value: float64(threads)})
+	}
+
+	return points
+}
+
+// findProcess - Return the process of target, read from its pidfile when target
+// is a path or the running process with that name and the lowest PID otherwise
+func findProcess(ctx context.Context, target string) (*process.Process, error) {
+	if strings.Contains(target, "/") {
+		pidBytes, err := ioutil.ReadFile(target)
+
+		if err != nil {
+			return nil, fmt.Errorf("[Collector] - Error reading pidfile %s: %v", target, err)
+		}
+
+		pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+
+		if err != nil {
+			return nil, fmt.Errorf("[Collector] - Invalid pidfile %s: %v", target, err)
+		}
+
+		return process.NewProcess(int32(pid))
+	}
+
+	processes, err := process.ProcessesWithContext(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("[Collector] - Error listing processes: %v", err)
+	}
+
+	var found *process.Process
+
+	for _, proc := range processes {
+		if name, err := proc.NameWithContext(ctx); err == nil && name == target {
+			if found == nil || proc.Pid < found.Pid {
+				found = proc
+			}
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("[Collector] - There is no process %s", target)
+	}
+
+	return found, nil
+}
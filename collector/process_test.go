@@ -0,0 +1,69 @@
+/*
+	Date	:	18/10/2026
+	File	:	process_test.go
+	Overview: 	Tests of the restarts of watched processes, told by a change
+				of PID in their pidfile.
+*/
+
+package collector
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestProcessRestarts(t *testing.T) {
+	pidfile := filepath.Join(t.TempDir(), "app.pid")
+	p := NewProcess([]string{pidfile})
+
+	restarts := labeledVariable(processTemplates[4], pidfile).Key
+	usage := labeledVariable(processTemplates[0], pidfile).Key
+
+	// Steps run in order against the same collector, pid 0 removes the pidfile
+	steps := []struct {
+		name     string
+		pid      int
+		restarts float64
+		usage    bool
+	}{
+		{"first reading", os.Getpid(), 0, false},
+		{"same PID", os.Getpid(), 0, true},
+		{"new PID", os.Getppid(), 1, false},
+		{"new PID again", os.Getppid(), 1, true},
+		{"not running", 0, 1, false},
+		{"started again", os.Getpid(), 2, false},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if step.pid == 0 {
+				os.Remove(pidfile)
+			} else if err := ioutil.WriteFile(pidfile, []byte(strconv.Itoa(step.pid)), 0600); err != nil {
+				t.Fatalf("write pidfile: %v", err)
+			}
+
+			points, err := p.Collect(context.Background())
+
+			if err != nil {
+				t.Fatalf("Collect error = %v", err)
+			}
+
+			values := make(map[string]float64)
+
+			for _, point := range points {
+				values[point.Key] = point.Value
+			}
+
+			if values[restarts] != step.restarts {
+				t.Errorf("restarts = %v, want %v", values[restarts], step.restarts)
+			}
+			if _, ok := values[usage]; ok != step.usage {
+				t.Errorf("CPU usage given = %v, want %v", ok, step.usage)
+			}
+		})
+	}
+}
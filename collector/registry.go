@@ -0,0 +1,329 @@
+/*
+	Date	:	18/10/2026
+	File	:	registry.go
+	Overview: 	Registry provides the Collector interface implemented by every
+				data source and the list of registered sources. Storage, menus,
+				table headers and queries are built from this list, so a new
+				source only has to be registered to show up everywhere.
+*/
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInterval - time between entries of collectors without an interval of their own
+const DefaultInterval = time.Second
+
+// LabelSeparator - separates a variable code from the disk, interface or process
+// it is about (e.g. rxb:eth0)
+const LabelSeparator = ":"
+
+// DeviceSeparator - separates a variable code from the device it belongs to, when
+// several devices have the same variables (e.g. 1@greenhouse)
+const DeviceSeparator = "@"
+
+// Variable type - one value collected by a source. Code is what users type to
+// ask for it (e.g. c) and Key is the json key where it is stored (e.g. cpu).
+type Variable struct {
+	Code string `json:"code"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Unit string `json:"unit"`
+}
+
+// Point type - one collected value, stored under Key
+type Point struct {
+	Key   string
+	Value float64
+}
+
+// Collector interface - a source of data. Name is the table where its entries
+// are stored. Collect may return points without a variable (e.g. totalRAM), which
+// are stored but cant be queried.
+type Collector interface {
+	Name() string
+	Variables() []Variable
+	Collect(ctx context.Context) ([]Point, error)
+}
+
+// Resolver interface - implemented by collectors whose variables name a device,
+// interface or process (e.g. rxb:eth0). Resolve finds variables that are not
+// listed by Variables anymore, or not yet, so their stored points can be queried.
+type Resolver interface {
+	Resolve(code string) (Variable, bool)
+}
+
+// Errors returned by Collect when a device did not answer. They are expected
+// from unreliable devices, so callers count them as missed readings and keep
+// collecting.
+var (
+	ErrNoAnswer     = errors.New("[Collector] - Device did not answer")
+	ErrDisconnected = errors.New("[Collector] - Device is disconnected")
+)
+
+// Scheduled interface - implemented by collectors that are not collected every
+// DefaultInterval (e.g. devices of a simulated fleet)
+type Scheduled interface {
+	Interval() time.Duration
+}
+
+// Reading type - points collected together, at Time
+type Reading struct {
+	Time   time.Time
+	Points []Point
+}
+
+// Backlog interface - implemented by collectors whose readings have their own
+// time, several of which may be due at once (e.g. a replay polled late). Readings
+// of CollectDue, oldest first, are stored at their time instead of the time of
+// the collection.
+type Backlog interface {
+	CollectDue(ctx context.Context) ([]Reading, error)
+}
+
+var (
+	mutex      sync.Mutex
+	collectors []Collector
+)
+
+// Register - Add c to the registered collectors, replacing the one with the same
+// name. Like database/sql drivers, a variable code used twice, by c itself or by
+// another collector, is a programming error, so Register panics in that case.
+func Register(c Collector) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	seen := make(map[string]bool)
+
+	for _, v := range c.Variables() {
+		if seen[v.Code] {
+			panic(fmt.Sprintf("[Collector] - Variable code %s is used twice by %s", v.Code, c.Name()))
+		}
+		seen[v.Code] = true
+	}
+
+	// Codes are checked before anything changes, so a panic leaves no trace
+	for _, other := range collectors {
+		if other.Name() == c.Name() {
+			continue
+		}
+
+		for _, v := range c.Variables() {
+			if _, taken := findCode(other, v.Code); taken {
+				panic(fmt.Sprintf("[Collector] - Variable code %s of %s is already used by %s", v.Code, c.Name(), other.Name()))
+			}
+		}
+	}
+
+	for i, other := range collectors {
+		if other.Name() == c.Name() {
+			collectors[i] = c
+			return
+		}
+	}
+
+	collectors = append(collectors, c)
+}
+
+// Unregister - Remove the collector called name, if there is one
+func Unregister(name string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for i, c := range collectors {
+		if c.Name() == name {
+			collectors = append(collectors[:i], collectors[i+1:]...)
+			return
+		}
+	}
+}
+
+// Collectors - Return registered collectors, in registration order
+func Collectors() []Collector {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return append([]Collector(nil), collectors...)
+}
+
+// Get - Return the registered collector called name
+func Get(name string) (Collector, bool) {
+	for _, c := range Collectors() {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// Variables - Return variables of every registered collector, in registration order
+func Variables() []Variable {
+	var variables []Variable
+
+	for _, c := range Collectors() {
+		variables = append(variables, c.Variables()...)
+	}
+
+	return variables
+}
+
+// Codes - Return a code string like c,r,1, ... with every registered variable
+func Codes() string {
+	var codes []string
+
+	for _, v := range Variables() {
+		codes = append(codes, v.Code)
+	}
+
+	return strings.Join(codes, ",")
+}
+
+// BaseCodes - Return a code string with every registered variable that is not
+// labeled, the ones queried when no variable is given. Variables of disks,
+// interfaces and processes (e.g. rxb:eth0) are only queried when asked for.
+func BaseCodes() string {
+	var codes []string
+
+	for _, v := range Variables() {
+		if !v.Labeled() {
+			codes = append(codes, v.Code)
+		}
+	}
+
+	return strings.Join(codes, ",")
+}
+
+// Lookup - Return the collector and the variable of code, or false if no
+// registered collector has it
+func Lookup(code string) (Collector, Variable, bool) {
+	for _, c := range Collectors() {
+		if v, ok := findCode(c, code); ok {
+			return c, v, true
+		}
+	}
+
+	// Variables of devices, interfaces or processes not seen by this process
+	for _, c := range Collectors() {
+		if r, ok := c.(Resolver); ok {
+			if v, ok := r.Resolve(code); ok {
+				return c, v, true
+			}
+		}
+	}
+
+	return nil, Variable{}, false
+}
+
+// Interval - Return the time between entries of collector c
+func Interval(c Collector) time.Duration {
+	if s, ok := c.(Scheduled); ok && s.Interval() > 0 {
+		return s.Interval()
+	}
+
+	return DefaultInterval
+}
+
+// DeviceCode - Return the code of variable code of device id, e.g. 1@greenhouse
+func DeviceCode(code string, id string) string {
+	return code + DeviceSeparator + id
+}
+
+// DeviceCodes - Return a code string like 1,2 with every code scoped to device id,
+// e.g. 1@greenhouse,2@greenhouse. Codes of other devices are dropped and an empty
+// code string gives every registered variable of the device.
+func DeviceCodes(codeStr string, id string) string {
+	var codes []string
+	seen := make(map[string]bool)
+
+	if codeStr == "" {
+		codeStr = Codes()
+	}
+
+	// Each code once, as 1 and 1@greenhouse give the same one
+	add := func(code string) {
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	for _, code := range strings.Split(codeStr, ",") {
+		parts := strings.SplitN(code, DeviceSeparator, 2)
+
+		// Already scoped, kept only if it is the same device
+		if len(parts) == 2 {
+			if parts[1] == id {
+				add(code)
+			}
+			continue
+		}
+
+		// Only variables listed by a device, so labeled codes (e.g. dt:/) are not
+		// resolved as if they had a device
+		for _, c := range Collectors() {
+			if _, ok := findCode(c, DeviceCode(code, id)); ok {
+				add(DeviceCode(code, id))
+				break
+			}
+		}
+	}
+
+	return strings.Join(codes, ",")
+}
+
+// Label - Return the name of a variable shown to users, with its unit if any
+func (v Variable) Label() string {
+	if v.Unit == "" {
+		return v.Name
+	}
+
+	return fmt.Sprintf("%s (%s)", v.Name, v.Unit)
+}
+
+// Labeled - Report whether the variable is about one disk, interface or process
+func (v Variable) Labeled() bool {
+	return strings.Contains(v.Code, LabelSeparator)
+}
+
+// labeledVariable - Return the variable of template for one device, interface or
+// process, e.g. template rxb (rxBytes) with label eth0 gives rxb:eth0 (rxBytes:eth0)
+func labeledVariable(template Variable, label string) Variable {
+	return Variable{Code: template.Code + LabelSeparator + label, Key: template.Key + LabelSeparator + label, Name: label + " " + template.Name, Unit: template.Unit}
+}
+
+// resolveLabeled - Return the variable of a code like rxb:eth0 built from one of
+// templates, or false if its prefix is not one of them
+func resolveLabeled(code string, templates []Variable) (Variable, bool) {
+	parts := strings.SplitN(code, LabelSeparator, 2)
+
+	if len(parts) < 2 || parts[1] == "" {
+		return Variable{}, false
+	}
+
+	for _, t := range templates {
+		if t.Code == parts[0] {
+			return labeledVariable(t, parts[1]), true
+		}
+	}
+
+	return Variable{}, false
+}
+
+// findCode - Return the variable of c with code
+func findCode(c Collector, code string) (Variable, bool) {
+	for _, v := range c.Variables() {
+		if v.Code == code {
+			return v, true
+		}
+	}
+
+	return Variable{}, false
+}
@@ -0,0 +1,172 @@
+/*
+	Date	:	18/10/2026
+	File	:	registry_test.go
+	Overview: 	Tests of the registration of collectors and of the codes of
+				their variables.
+*/
+
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// fakeCollector type - collector with fixed variables and no data
+type fakeCollector struct {
+	name  string
+	codes []string
+}
+
+func (f fakeCollector) Name() string {
+	return f.name
+}
+
+func (f fakeCollector) Variables() []Variable {
+	var variables []Variable
+
+	for _, code := range f.codes {
+		variables = append(variables, Variable{Code: code, Key: "key" + code, Name: code})
+	}
+
+	return variables
+}
+
+func (f fakeCollector) Collect(ctx context.Context) ([]Point, error) {
+	return nil, nil
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  []fakeCollector
+		c         fakeCollector
+		wantPanic bool
+		wantCodes []string
+	}{
+		{
+			name:      "new collector",
+			c:         fakeCollector{"TEST_A", []string{"t1", "t2"}},
+			wantCodes: []string{"t1", "t2"},
+		},
+		{
+			name:      "code used twice by the collector",
+			c:         fakeCollector{"TEST_A", []string{"t1", "t1"}},
+			wantPanic: true,
+		},
+		{
+			name:      "code used by another collector",
+			existing:  []fakeCollector{{"TEST_B", []string{"t2"}}},
+			c:         fakeCollector{"TEST_A", []string{"t1", "t2"}},
+			wantPanic: true,
+		},
+		{
+			name:      "collector with the same name is replaced",
+			existing:  []fakeCollector{{"TEST_A", []string{"t1"}}},
+			c:         fakeCollector{"TEST_A", []string{"t1", "t3"}},
+			wantCodes: []string{"t1", "t3"},
+		},
+		{
+			name:      "replacement using a code of another collector",
+			existing:  []fakeCollector{{"TEST_A", []string{"t1"}}, {"TEST_B", []string{"t2"}}},
+			c:         fakeCollector{"TEST_A", []string{"t2"}},
+			wantPanic: true,
+			wantCodes: []string{"t1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer Unregister("TEST_A")
+			defer Unregister("TEST_B")
+
+			for _, c := range tt.existing {
+				Register(c)
+			}
+
+			panicked := func() (panicked bool) {
+				defer func() { panicked = recover() != nil }()
+				Register(tt.c)
+				return false
+			}()
+
+			if panicked != tt.wantPanic {
+				t.Fatalf("Register panicked = %v, want %v", panicked, tt.wantPanic)
+			}
+
+			c, ok := Get("TEST_A")
+
+			if tt.wantCodes == nil {
+				if ok {
+					t.Errorf("TEST_A was registered")
+				}
+				return
+			}
+
+			// A panic leaves the registered collector as it was
+			if !ok || len(c.Variables()) != len(tt.wantCodes) {
+				t.Fatalf("TEST_A registered = %v, want codes %v", ok, tt.wantCodes)
+			}
+			for i, v := range c.Variables() {
+				if v.Code != tt.wantCodes[i] {
+					t.Errorf("code %d = %s, want %s", i, v.Code, tt.wantCodes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBaseCodes(t *testing.T) {
+	defer Unregister("TEST_A")
+
+	Register(fakeCollector{"TEST_A", []string{"t1", "t2:eth0", "t3@dev1"}})
+
+	base := "," + BaseCodes() + ","
+
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"t1", true},
+		{"t2:eth0", false},
+		{"t3@dev1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := strings.Contains(base, ","+tt.code+","); got != tt.want {
+				t.Errorf("BaseCodes has %s = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeviceCodes(t *testing.T) {
+	defer Unregister("TEST_A")
+	defer Unregister("TEST_B")
+
+	Register(fakeCollector{"TEST_A", []string{"t1", "t2"}})
+	Register(fakeCollector{"TEST_B", []string{"t1@dev1", "t2@dev1", "t1@dev2"}})
+
+	tests := []struct {
+		name    string
+		codeStr string
+		id      string
+		want    string
+	}{
+		{"codes of the device", "t1,t2", "dev1", "t1@dev1,t2@dev1"},
+		{"codes the device lacks", "t1,t2", "dev2", "t1@dev2"},
+		{"scoped codes", "t1@dev1,t1@dev2", "dev1", "t1@dev1"},
+		{"each code once", "t1,t1@dev1", "dev1", "t1@dev1"},
+		{"unknown device", "t1", "dev3", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeviceCodes(tt.codeStr, tt.id); got != tt.want {
+				t.Errorf("DeviceCodes(%q, %q) = %q, want %q", tt.codeStr, tt.id, got, tt.want)
+			}
+		})
+	}
+}
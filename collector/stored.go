@@ -0,0 +1,66 @@
+/*
+	Date	:	18/10/2026
+	File	:	stored.go
+	Overview: 	Stored stands for a collector of another process. Its
+				variables are the ones saved in the database while that
+				process collected, so they can be queried without the
+				profile, fleet or recording that collected them.
+*/
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Stored type - variables of a table saved in the database. It is never collected.
+type Stored struct {
+	name      string
+	variables []Variable
+}
+
+// NewStored - Return the stored variables of table name
+func NewStored(name string, variables []Variable) *Stored {
+	return &Stored{name: name, variables: variables}
+}
+
+// Name - table where the variables are stored
+func (s *Stored) Name() string {
+	return s.name
+}
+
+// Variables - variables saved for the table
+func (s *Stored) Variables() []Variable {
+	return s.variables
+}
+
+// Collect - stored variables are only queried
+func (s *Stored) Collect(ctx context.Context) ([]Point, error) {
+	return nil, fmt.Errorf("[Collector] - %s is not collected by this process", s.name)
+}
+
+// Resolve - Return a labeled variable not saved, like rxb:wlan0, built from a
+// saved variable with the same prefix, like rxb:eth0
+func (s *Stored) Resolve(code string) (Variable, bool) {
+	var templates []Variable
+
+	for _, v := range s.variables {
+		parts := strings.SplitN(v.Code, LabelSeparator, 2)
+
+		if len(parts) < 2 {
+			continue
+		}
+
+		// Label taken out of code, key and name, see labeledVariable
+		templates = append(templates, Variable{
+			Code: parts[0],
+			Key:  strings.TrimSuffix(v.Key, LabelSeparator+parts[1]),
+			Name: strings.TrimPrefix(v.Name, parts[1]+" "),
+			Unit: v.Unit,
+		})
+	}
+
+	return resolveLabeled(code, templates)
+}
@@ -0,0 +1,38 @@
+/*
+	Date	:	18/10/2026
+	File	:	stored_test.go
+	Overview: 	Tests of the variables of collectors of other processes.
+*/
+
+package collector
+
+import "testing"
+
+func TestStoredResolve(t *testing.T) {
+	s := NewStored("NET", []Variable{
+		labeledVariable(Variable{Code: "rxb", Key: "rxBytes", Name: "rx", Unit: "B/s"}, "eth0"),
+		{Code: "x", Key: "x", Name: "x"},
+	})
+
+	tests := []struct {
+		code   string
+		want   Variable
+		wantOK bool
+	}{
+		{code: "rxb:wlan0", want: Variable{Code: "rxb:wlan0", Key: "rxBytes:wlan0", Name: "wlan0 rx", Unit: "B/s"}, wantOK: true},
+		{code: "rxb:eth0", want: Variable{Code: "rxb:eth0", Key: "rxBytes:eth0", Name: "eth0 rx", Unit: "B/s"}, wantOK: true},
+		{code: "txb:eth0"},
+		{code: "x:eth0"},
+		{code: "rxb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := s.Resolve(tt.code)
+
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Resolve(%q) = %+v, %v, want %+v, %v", tt.code, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+/*
+	Date	:	18/10/2026
+	File	:	commands.go
+	Overview: 	Commands provides the non interactive subcommands, so the
+				collector can run as a service and the stored data can be
+				queried from scripts: collect, last, avg, dump, info and
+				simulate.
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/sim"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/toolset"
+)
+
+// runCommand - run subcommand name with its arguments. Returns false when name
+// is not a subcommand, so the interactive menu should run instead.
+func runCommand(name string, args []string) bool {
+	var err error
+
+	switch name {
+	case "collect":
+		err = commandCollect(args)
+	case "last":
+		err = commandLast(args)
+	case "avg":
+		err = commandAvg(args)
+	case "dump":
+		err = commandDump(args)
+	case "info":
+		err = commandInfo(args)
+	case "simulate":
+		err = commandSimulate(args)
+	default:
+		return false
+	}
+
+	// Report errors on stderr, so stdout only has results
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	return true
+}
+
+// commandCollect - collect data without the menu until the process is stopped
+func commandCollect(args []string) error {
+	flags := flag.NewFlagSet("collect", flag.ExitOnError)
+	dbFlag := flags.String("db", "ubiDB", "name of the database file, without .db")
+	retentionAgeFlag := flags.String("retention", "", "max age kept per table, e.g. OS=72h,SAMPLES=24h")
+	retentionSizeFlag := flags.String("retention-size", "", "max size kept per table, e.g. OS=50MB,SAMPLES=10MB")
+	httpFlag := flags.String("http", "", "serve the HTTP API on this address, e.g. :8080")
+	originsFlag := flags.String("origins", "", "other origins of pages allowed to open WebSocket streams, e.g. https://wallboard.local (* for any)")
+	watchFlag := flags.String("watch", "", "processes to watch, by name or pidfile, e.g. gatewayd,/run/app.pid")
+	cgroupFlag := flags.String("cgroup", "auto", "read CPU and RAM from the container cgroup: auto, on or off")
+	device := deviceFlags(flags)
+	flags.Parse(args)
+
+	devices, err := loadDevices(device)
+
+	if err != nil {
+		return err
+	}
+
+	db, err := openSession(*dbFlag)
+
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = startBackground(db, devices, *retentionAgeFlag, *retentionSizeFlag, *httpFlag, *originsFlag, *watchFlag, *cgroupFlag)
+
+	if err != nil {
+		return err
+	}
+
+	toolset.WriteToLog(fmt.Sprintf("%s \t || [Collect] \t Running without menu \n", toolset.GetFormatedTime()))
+
+	// Wait for the service to be stopped, closing database on the way out
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	toolset.WriteToLog(fmt.Sprintf("%s \t || [Collect] \t Stopped \n", toolset.GetFormatedTime()))
+	return nil
+}
+
+// commandLast - print the last n metrics of one or more variables
+func commandLast(args []string) error {
+	flags := flag.NewFlagSet("last", flag.ExitOnError)
+	dbFlag := flags.String("db", "ubiDB", "name of the database file, without .db")
+	nFlag := flags.Int("n", 10, "number of metrics")
+	varsFlag := flags.String("vars", "", "variables, e.g. c,r,1 (default every variable but disk, interface and process ones)")
+	formatFlag := flags.String("format", "table", "output format: table, json or csv")
+	deviceFlag := flags.String("device", "", "only variables of this fleet device")
+	flags.Parse(args)
+
+	db, err := database.OpenReadOnly(*dbFlag)
+
+	if err != nil {
+		return err
+	}
+	defer database.CloseReadOnly(db)
+
+	if err := useStored(db); err != nil {
+		return err
+	}
+
+	vars, err := allVariables(db, *varsFlag, *deviceFlag)
+
+	if err != nil {
+		return err
+	}
+
+	series, err := toolset.GetLastN(db, fmt.Sprintf("%d,%s", *nFlag, vars))
+
+	if err != nil {
+		return err
+	}
+
+	return toolset.PrintSeries(series, *formatFlag)
+}
+
+// commandAvg - print the average of one or more variables
+func commandAvg(args []string) error {
+	flags := flag.NewFlagSet("avg", flag.ExitOnError)
+	dbFlag := flags.String("db", "ubiDB", "name of the database file, without .db")
+	varsFlag := flags.String("vars", "", "variables, e.g. c,r,1 (default every variable but disk, interface and process ones)")
+	formatFlag := flags.String("format", "table", "output format: table, json or csv")
+	deviceFlag := flags.String("device", "", "only variables of this fleet device")
+	flags.Parse(args)
+
+	db, err := database.OpenReadOnly(*dbFlag)
+
+	if err != nil {
+		return err
+	}
+	defer database.CloseReadOnly(db)
+
+	if err := useStored(db); err != nil {
+		return err
+	}
+
+	vars, err := allVariables(db, *varsFlag, *deviceFlag)
+
+	if err != nil {
+		return err
+	}
+
+	aggregates, err := toolset.GetAggregate(db, vars)
+
+	if err != nil {
+		return err
+	}
+
+	return toolset.PrintAverages(aggregates, *formatFlag)
+}
+
+// commandDump - print every stored metric of one or more variables
+func commandDump(args []string) error {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	dbFlag := flags.String("db", "ubiDB", "name of the database file, without .db")
+	varsFlag := flags.String("vars", "", "variables, e.g. c,r,1 (default every variable but disk, interface and process ones)")
+	formatFlag := flags.String("format", "csv", "output format: table, json or csv")
+	deviceFlag := flags.String("device", "", "only variables of this fleet device")
+	flags.Parse(args)
+
+	db, err := database.OpenReadOnly(*dbFlag)
+
+	if err != nil {
+		return err
+	}
+	defer database.CloseReadOnly(db)
+
+	if err := useStored(db); err != nil {
+		return err
+	}
+
+	vars, err := allVariables(db, *varsFlag, *deviceFlag)
+
+	if err != nil {
+		return err
+	}
+
+	series, err := toolset.GetAll(db, vars)
+
+	if err != nil {
+		return err
+	}
+
+	return toolset.PrintSeries(series, *formatFlag)
+}
+
+// commandInfo - print the metadata of the database and of the machine of its
+// last session
+func commandInfo(args []string) error {
+	flags := flag.NewFlagSet("info", flag.ExitOnError)
+	dbFlag := flags.String("db", "ubiDB", "name of the database file, without .db")
+	flags.Parse(args)
+
+	db, err := database.OpenReadOnly(*dbFlag)
+
+	if err != nil {
+		return err
+	}
+	defer database.CloseReadOnly(db)
+
+	config, err := database.GetConfig(db)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Last access: \t %s\n", config.LastAccessTime)
+	fmt.Printf("Hostname: \t %s\n", config.Hostname)
+	fmt.Printf("OS: \t\t %s (%s)\n", config.OS, config.Platform)
+	fmt.Printf("Kernel: \t %s\n", config.Kernel)
+	fmt.Printf("CPU: \t\t %s (%d cores)\n", config.CPUModel, config.Cores)
+
+	return nil
+}
+
+// commandSimulate - generate the readings of the simulated device over a period
+// with a simulated clock, printing them as csv or comparing them with a golden file
+func commandSimulate(args []string) error {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	profileFlag := flags.String("profile", "", "profile of the simulated device, .json or .yaml")
+	seedFlag := flags.Int64("seed", 0, "seed of the simulated device (default seed of the profile, or 1)")
+	startFlag := flags.String("start", "2020-01-01T00:00:00Z", "instant of the first reading, RFC 3339")
+	durationFlag := flags.Duration("duration", 24*time.Hour, "period simulated")
+	intervalFlag := flags.Duration("interval", time.Second, "time between readings")
+	goldenFlag := flags.String("golden", "", "compare readings with this csv file instead of printing them")
+	updateFlag := flags.Bool("update", false, "write readings into the file given by -golden")
+	dbFlag := flags.String("db", "", "also store readings into this database, without .db")
+	flags.Parse(args)
+
+	profile, err := loadProfile(*profileFlag)
+
+	if err != nil {
+		return err
+	}
+
+	start, err := time.Parse(time.RFC3339Nano, *startFlag)
+
+	if err != nil {
+		return fmt.Errorf("[Simulate] - Invalid start %q, use RFC 3339", *startFlag)
+	}
+
+	// Simulations are always reproducible, even without a seed
+	seed := *seedFlag
+
+	if seed == 0 && profile.Seed == 0 {
+		seed = 1
+	}
+
+	readings, err := sim.Simulate(profile, seed, start, *durationFlag, *intervalFlag)
+
+	if err != nil {
+		return err
+	}
+
+	if *dbFlag != "" {
+		if err := storeReadings(*dbFlag, sim.NewDevice(profile, seed, nil), readings); err != nil {
+			return err
+		}
+	}
+
+	var output bytes.Buffer
+
+	if err := sim.WriteCSV(&output, profile, readings); err != nil {
+		return err
+	}
+
+	switch {
+	case *goldenFlag != "" && *updateFlag:
+		return ioutil.WriteFile(*goldenFlag, output.Bytes(), 0644)
+	case *goldenFlag != "":
+		golden, err := ioutil.ReadFile(*goldenFlag)
+
+		if err != nil {
+			return fmt.Errorf("[Simulate] - Error reading golden file: %v", err)
+		}
+
+		if err := sim.CompareGolden(output.Bytes(), golden); err != nil {
+			return err
+		}
+
+		fmt.Printf("%d readings match %s\n", len(readings), *goldenFlag)
+		return nil
+	}
+
+	_, err = os.Stdout.Write(output.Bytes())
+	return err
+}
+
+// storeReadings - store simulated readings of device into SAMPLES table of database
+// nameDB, keyed by their simulated time
+func storeReadings(nameDB string, device collector.Collector, readings []sim.Reading) error {
+	db, err := database.SetupDB(nameDB)
+
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var entries []database.Entry
+
+	for _, r := range readings {
+		values := make(map[string]float64)
+
+		for _, p := range r.Points {
+			values[p.Key] = p.Value
+		}
+
+		entries = append(entries, database.Entry{Time: r.Time, Values: values})
+	}
+
+	if err := database.AddEntries(db, "SAMPLES", entries); err != nil {
+		return err
+	}
+
+	// Queries find the channels of the profile in database
+	saveVariables(db, []collector.Collector{device})
+	return nil
+}
@@ -10,31 +10,53 @@
 package database
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/boltdb/bolt"
 )
 
-// Config type
-type Config struct {
-	LastAccessTime string `json:"lastAccessTime"`
+// LegacyKeyFormat - layout of the text timestamp keys written by older versions.
+// Those keys are rewritten by SetupDB into binary keys (see TimeKey).
+const LegacyKeyFormat = "06/01/02 15:04:05"
+
+// SchemaVersion - version of the key layout stored in VERSION entry of root bucket
+const SchemaVersion = "2"
+
+// SubTableSeparator - separates a table from one of its sub-tables, e.g. the
+// table of device greenhouse inside SAMPLES is SAMPLES/greenhouse
+const SubTableSeparator = "/"
+
+// Entry type - values of one entry of a table, keyed by their json key, and the
+// instant they were collected
+type Entry struct {
+	Time   time.Time
+	Values map[string]float64
 }
 
-// PerformanceOS type
-type PerformanceOS struct {
-	CPU      float64 `json:"cpu"`
-	TotalRAM uint64  `json:"totalRAM"`
-	UsedRAM  uint64  `json:"usedRAM"`
+// Config type - metadata of the database and of the machine of the last session
+type Config struct {
+	LastAccessTime string `json:"lastAccessTime"`
+	Hostname       string `json:"hostname"`
+	OS             string `json:"os"`
+	Platform       string `json:"platform"`
+	Kernel         string `json:"kernel"`
+	CPUModel       string `json:"cpuModel"`
+	Cores          int    `json:"cores"`
 }
 
-// Sample type
-type Sample struct {
-	Sample1 int `json:"sample1"`
-	Sample2 int `json:"sample2"`
-	Sample3 int `json:"sample3"`
-	Sample4 int `json:"sample4"`
+// StoredVariable type - a variable of table, saved while collecting so processes
+// that do not collect it (e.g. query commands) still know where it is stored
+type StoredVariable struct {
+	Table string `json:"table"`
+	Code  string `json:"code"`
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Unit  string `json:"unit"`
 }
 
 // SetupDB - Create a new DB with name given as argument, if DB
@@ -45,7 +67,7 @@ func SetupDB(nameDB string) (*bolt.DB, error) {
 
 	//Error handling
 	if err != nil {
-		fmt.Printf("[Database] - Error opening %s.db \n", nameDB)
+		return nil, fmt.Errorf("[Database] - Error opening %s.db: %v", nameDB, err)
 	}
 
 	//Create root bucket and needed buckets into root
@@ -75,9 +97,22 @@ func SetupDB(nameDB string) (*bolt.DB, error) {
 	})
 
 	if err != nil {
+		db.Close()
 		return nil, fmt.Errorf("[Database] - Error performing update: %v", err)
 	}
 
+	//Rewrite text keys from older versions, only done once per database file
+	migrated, err := migrateKeys(db)
+
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("[Database] - Error migrating keys: %v", err)
+	}
+
+	if migrated > 0 {
+		fmt.Printf("[Database] - %d entries migrated to binary timestamp keys \n", migrated)
+	}
+
 	//Retuns database created and nil since are no errors
 	return db, nil
 }
@@ -108,59 +143,339 @@ func SetConfig(db *bolt.DB, config Config) error {
 	return err
 }
 
-// AddSystemStat - Perform a entry on OS table in database with new info
-func AddSystemStat(db *bolt.DB, cpu float64, totalRAM uint64, usedRAM uint64) error {
-	// Create a PerformanceOS struct in order to create a json bytes to store
-	stats := PerformanceOS{CPU: cpu, TotalRAM: totalRAM, UsedRAM: usedRAM}
-	statsBytes, err := json.Marshal(stats)
+// GetConfig - Return the CONFIG entry of the database, written by SetConfig
+func GetConfig(db *bolt.DB) (Config, error) {
+	var config Config
 
-	// Handle json encoding errors
-	if err != nil {
-		return fmt.Errorf("[Database] - Error encoding stats data: %v", err)
-	}
+	err := db.View(func(tx *bolt.Tx) error {
+		configBytes := tx.Bucket([]byte("DB")).Get([]byte("CONFIG"))
 
-	// Update database with new CPU and RAM info
-	err = db.Update(func(tx *bolt.Tx) error {
-		//Write to OS table - TIME : [CPU, TotalRAM, UsedRAM]
-		err := tx.Bucket([]byte("DB")).Bucket([]byte("OS")).Put([]byte(time.Now().Format("06/01/02 15:04:05")), statsBytes)
+		if configBytes == nil {
+			return fmt.Errorf("[Database] - There is no config entry")
+		}
+
+		//Handle unmarshall possible errors
+		if err := json.Unmarshal(configBytes, &config); err != nil {
+			return fmt.Errorf("[Database] - Error decoding config data: %v", err)
+		}
+
+		return nil
+	})
+
+	return config, err
+}
+
+// SaveVariables - Save variables into VARIABLES entry of root bucket. Variables
+// saved before are kept, so data of older sessions can still be queried, unless
+// they are of the same tables or use the same codes as the new ones.
+func SaveVariables(db *bolt.DB, variables []StoredVariable) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket([]byte("DB"))
+
+		tables := make(map[string]bool)
+		codes := make(map[string]bool)
+
+		for _, v := range variables {
+			tables[v.Table] = true
+			codes[v.Code] = true
+		}
+
+		saved, err := decodeVariables(root.Get([]byte("VARIABLES")))
 
-		// Handle database update error
 		if err != nil {
-			return fmt.Errorf("[Database] - Error inserting data to OS bucket: %v", err)
+			return err
+		}
+
+		for _, v := range saved {
+			if !tables[v.Table] && !codes[v.Code] {
+				variables = append(variables, v)
+			}
+		}
+
+		variablesBytes, err := json.Marshal(variables)
+
+		if err != nil {
+			return fmt.Errorf("[Database] - Error encoding variables: %v", err)
+		}
+
+		if err := root.Put([]byte("VARIABLES"), variablesBytes); err != nil {
+			return fmt.Errorf("[Database] - Failed saving variables: %v", err)
 		}
 
 		return nil
 	})
+}
 
-	// Return err as nil if success!
-	return err
+// GetVariables - Return the variables saved by SaveVariables, none for databases
+// written by older versions
+func GetVariables(db *bolt.DB) ([]StoredVariable, error) {
+	var variables []StoredVariable
+
+	err := db.View(func(tx *bolt.Tx) error {
+		var err error
+
+		variables, err = decodeVariables(tx.Bucket([]byte("DB")).Get([]byte("VARIABLES")))
+		return err
+	})
+
+	return variables, err
 }
 
-// AddSample - Perform a entry on SAMPLE table on database with new samples
-func AddSample(db *bolt.DB, samples []int) error {
-	// Create a Sample struct in order to create a json bytes to store
-	sample := Sample{Sample1: samples[0], Sample2: samples[1], Sample3: samples[2], Sample4: samples[3]}
-	sampleBytes, err := json.Marshal(sample)
+// decodeVariables - Decode the value of VARIABLES entry, which may not exist
+func decodeVariables(value []byte) ([]StoredVariable, error) {
+	var variables []StoredVariable
 
-	// Handle json encoding errors
-	if err != nil {
-		return fmt.Errorf("[Database] - Error encoding sample data: %v", err)
+	if value == nil {
+		return nil, nil
 	}
 
-	// Update database with new samples from external device (sim package)
-	err = db.Update(func(tx *bolt.Tx) error {
-		//Write to SAMPLES table - TIME : S1 : S2 : S3 : S4
-		err := tx.Bucket([]byte("DB")).Bucket([]byte("SAMPLES")).Put([]byte(time.Now().Format("06/01/02 15:04:05")), sampleBytes)
+	if err := json.Unmarshal(value, &variables); err != nil {
+		return nil, fmt.Errorf("[Database] - Error decoding variables: %v", err)
+	}
 
-		// Handle database update error
-		if err != nil {
-			return fmt.Errorf("[Database] - Error inserting data to SAMPLES bucket: %v", err)
+	return variables, nil
+}
+
+// CreateTable - Create table name and its rollup tables, if they dont exist already.
+// Sub-tables (e.g. SAMPLES/greenhouse) are created inside their parent table.
+func CreateTable(db *bolt.DB, name string) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, table := range append([]string{name}, rollupTables(name)...) {
+			bucket := tx.Bucket([]byte("DB"))
+
+			// Walk the path of the table, creating every bucket missing
+			for _, part := range strings.Split(table, SubTableSeparator) {
+				var err error
+
+				if bucket, err = bucket.CreateBucketIfNotExists([]byte(part)); err != nil {
+					return fmt.Errorf("[Database] - Error creating %s bucket into root: %v", table, err)
+				}
+			}
 		}
 
 		return nil
+	})
+}
+
+// SubTable - Return the name of sub-table id of table name, stored as a nested
+// bucket of name (e.g. SAMPLES/greenhouse)
+func SubTable(name string, id string) string {
+	return name + SubTableSeparator + id
+}
+
+// Table - Return the bucket of table name inside root bucket, following the
+// nested buckets of sub-tables, or nil when it does not exist
+func Table(tx *bolt.Tx, name string) *bolt.Bucket {
+	bucket := tx.Bucket([]byte("DB"))
+
+	for _, part := range strings.Split(name, SubTableSeparator) {
+		if bucket == nil {
+			return nil
+		}
+		bucket = bucket.Bucket([]byte(part))
+	}
 
+	return bucket
+}
+
+// SubTables - Return the names of the sub-tables of table name
+func SubTables(db *bolt.DB, name string) ([]string, error) {
+	var tables []string
+
+	err := db.View(func(tx *bolt.Tx) error {
+		table := Table(tx, name)
+
+		if table == nil {
+			return fmt.Errorf("[Database] - There is no %s bucket", name)
+		}
+
+		// Nested buckets have no value
+		return table.ForEach(func(k, v []byte) error {
+			if v == nil {
+				tables = append(tables, SubTable(name, string(k)))
+			}
+			return nil
+		})
 	})
 
-	// Return err as nil if success!
+	return tables, err
+}
+
+// AddEntry - Perform an entry on table with the values collected, keyed by
+// their json key (e.g. cpu, usedRAM or sample1), the layout older versions
+// wrote into OS and SAMPLES, so old and new entries are read the same way.
+// Returns the instant of the key the entry was stored under.
+func AddEntry(db *bolt.DB, table string, values map[string]float64) (time.Time, error) {
+	return AddEntryAt(db, table, time.Now(), values)
+}
+
+// AddEntryAt - Perform an entry on table like AddEntry, keyed by instant at (e.g.
+// a reading replayed with its own time) instead of now
+func AddEntryAt(db *bolt.DB, table string, at time.Time, values map[string]float64) (time.Time, error) {
+	stored, err := addEntries(db, table, []Entry{{Time: at, Values: values}})
+
+	if err != nil || len(stored) == 0 {
+		return time.Time{}, err
+	}
+
+	return stored[0], nil
+}
+
+// AddEntries - Perform every entry on table in a single transaction, keyed by
+// the instant of each one (e.g. data generated or replayed with its own time).
+// NaN and infinite values cant be encoded as json, so they are not stored.
+func AddEntries(db *bolt.DB, table string, entries []Entry) error {
+	_, err := addEntries(db, table, entries)
+
 	return err
 }
+
+// addEntries - Perform every entry on table in a single transaction and return
+// the instants of the keys of the entries stored. A key is moved forward when
+// another entry has the same instant.
+func addEntries(db *bolt.DB, table string, entries []Entry) ([]time.Time, error) {
+	var stored []time.Time
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket := Table(tx, table)
+
+		if bucket == nil {
+			return fmt.Errorf("[Database] - There is no %s bucket", table)
+		}
+
+		for _, entry := range entries {
+			values, _ := FiniteValues(entry.Values)
+
+			// Entries with nothing left to store are skipped
+			if len(values) == 0 {
+				continue
+			}
+
+			entryBytes, err := json.Marshal(values)
+
+			// Handle json encoding errors
+			if err != nil {
+				return fmt.Errorf("[Database] - Error encoding %s data: %v", table, err)
+			}
+
+			key := freeKey(bucket, entry.Time)
+
+			// Handle database update error
+			if err := bucket.Put(key, entryBytes); err != nil {
+				return fmt.Errorf("[Database] - Error inserting data to %s bucket: %v", table, err)
+			}
+
+			stored = append(stored, KeyTime(key))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// FiniteValues - Return values without NaN and infinite ones, and the number of
+// values left out
+func FiniteValues(values map[string]float64) (map[string]float64, int) {
+	finite := make(map[string]float64, len(values))
+
+	for key, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		finite[key] = value
+	}
+
+	return finite, len(values) - len(finite)
+}
+
+// TimeKey - Encode a time instant as a key for OS and SAMPLES tables. Keys are
+// Unix nanoseconds in big-endian, so byte order is chronological order.
+func TimeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+
+	return key
+}
+
+// KeyTime - Decode a key written by TimeKey back to a time instant
+func KeyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key)))
+}
+
+// freeKey - Return the key for instant t in table, moving it forward one
+// nanosecond at a time while it is taken, so no entry is ever overwritten
+func freeKey(table *bolt.Bucket, t time.Time) []byte {
+	key := TimeKey(t)
+
+	for table.Get(key) != nil {
+		t = t.Add(time.Nanosecond)
+		key = TimeKey(t)
+	}
+
+	return key
+}
+
+// migrateKeys - Rewrite text keys (LegacyKeyFormat) of OS and SAMPLES tables
+// into binary keys. It runs only when VERSION entry is older than SchemaVersion
+// and returns the number of entries rewritten.
+func migrateKeys(db *bolt.DB) (int, error) {
+	migrated := 0
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket([]byte("DB"))
+
+		//Nothing to do if this database was already migrated
+		if string(root.Get([]byte("VERSION"))) == SchemaVersion {
+			return nil
+		}
+
+		for _, name := range []string{"OS", "SAMPLES"} {
+			table := root.Bucket([]byte(name))
+
+			//Collect legacy keys first, since a bucket cant be changed while iterating it
+			var legacyKeys [][]byte
+
+			table.ForEach(func(k, v []byte) error {
+				if len(k) != 8 {
+					legacyKeys = append(legacyKeys, append([]byte(nil), k...))
+				}
+				return nil
+			})
+
+			for _, k := range legacyKeys {
+				//Legacy keys were written with local time and no timezone
+				t, err := time.ParseInLocation(LegacyKeyFormat, string(k), time.Local)
+
+				if err != nil {
+					return fmt.Errorf("[Database] - Unknown key %q in %s bucket: %v", k, name, err)
+				}
+
+				//Copy value into the new key and remove the old one
+				value := append([]byte(nil), table.Get(k)...)
+
+				if err := table.Put(freeKey(table, t), value); err != nil {
+					return fmt.Errorf("[Database] - Error rewriting key %q in %s bucket: %v", k, name, err)
+				}
+
+				if err := table.Delete(k); err != nil {
+					return fmt.Errorf("[Database] - Error deleting key %q in %s bucket: %v", k, name, err)
+				}
+
+				migrated++
+			}
+		}
+
+		return root.Put([]byte("VERSION"), []byte(SchemaVersion))
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return migrated, nil
+}
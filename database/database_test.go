@@ -0,0 +1,256 @@
+/*
+	Date	:	18/10/2026
+	File	:	database_test.go
+	Overview: 	Tests of the migration of legacy text keys into binary keys
+				and of the entries written into tables.
+*/
+
+package database
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// openTestDB - Return an empty database with root bucket and OS and SAMPLES
+// tables, removed when the test ends
+func openTestDB(t *testing.T) *bolt.DB {
+	t.Helper()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists([]byte("DB"))
+
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{"OS", "SAMPLES"} {
+			if _, err := root.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	return db
+}
+
+// putRaw - Store value under key in table, as written by any version
+func putRaw(t *testing.T, db *bolt.DB, table string, key []byte, value string) {
+	t.Helper()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("DB")).Bucket([]byte(table)).Put(key, []byte(value))
+	})
+
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+}
+
+func TestMigrateKeys(t *testing.T) {
+	legacy := time.Date(2020, 6, 15, 10, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		version  string
+		keys     map[string][]byte
+		migrated int
+		want     map[string]int
+		wantErr  bool
+	}{
+		{
+			name:     "text keys are rewritten",
+			keys:     map[string][]byte{"OS": []byte(legacy.Format(LegacyKeyFormat)), "SAMPLES": []byte(legacy.Format(LegacyKeyFormat))},
+			migrated: 2,
+			want:     map[string]int{"OS": 1, "SAMPLES": 1},
+		},
+		{
+			name:     "binary keys are kept",
+			keys:     map[string][]byte{"OS": TimeKey(legacy)},
+			migrated: 0,
+			want:     map[string]int{"OS": 1, "SAMPLES": 0},
+		},
+		{
+			name:     "migrated databases are left alone",
+			version:  SchemaVersion,
+			keys:     map[string][]byte{"OS": []byte(legacy.Format(LegacyKeyFormat))},
+			migrated: 0,
+			want:     map[string]int{"OS": 1},
+		},
+		{
+			name:    "unknown keys fail",
+			keys:    map[string][]byte{"OS": []byte("not a time")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+
+			for table, key := range tt.keys {
+				putRaw(t, db, table, key, `{"cpu":1}`)
+			}
+
+			if tt.version != "" {
+				db.Update(func(tx *bolt.Tx) error {
+					return tx.Bucket([]byte("DB")).Put([]byte("VERSION"), []byte(tt.version))
+				})
+			}
+
+			migrated, err := migrateKeys(db)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("migrateKeys error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if migrated != tt.migrated {
+				t.Errorf("migrated = %d, want %d", migrated, tt.migrated)
+			}
+
+			db.View(func(tx *bolt.Tx) error {
+				for table, count := range tt.want {
+					keys := 0
+
+					tx.Bucket([]byte("DB")).Bucket([]byte(table)).ForEach(func(k, v []byte) error {
+						keys++
+
+						// Databases not migrated by this call keep their keys
+						if tt.version == "" && len(k) != 8 {
+							t.Errorf("%s key %q is not binary", table, k)
+						}
+						if tt.version == "" && len(k) == 8 && !KeyTime(k).Equal(legacy) {
+							t.Errorf("%s key time = %v, want %v", table, KeyTime(k), legacy)
+						}
+						return nil
+					})
+
+					if keys != count {
+						t.Errorf("%s has %d keys, want %d", table, keys, count)
+					}
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestAddEntry(t *testing.T) {
+	db := openTestDB(t)
+
+	first, err := AddEntry(db, "OS", map[string]float64{"c": 1})
+
+	if err != nil {
+		t.Fatalf("AddEntry error = %v", err)
+	}
+
+	// Stored under the instant returned
+	db.View(func(tx *bolt.Tx) error {
+		if Table(tx, "OS").Get(TimeKey(first)) == nil {
+			t.Errorf("no entry under %v", first)
+		}
+		return nil
+	})
+
+	// Same instant taken, so the key moves forward
+	err = AddEntries(db, "OS", []Entry{{Time: first, Values: map[string]float64{"c": 2}}})
+
+	if err != nil {
+		t.Fatalf("AddEntries error = %v", err)
+	}
+	if entries := countEntries(t, db, "OS"); entries != 2 {
+		t.Errorf("OS has %d entries, want 2", entries)
+	}
+
+	// Nothing left to store after NaN is dropped
+	if stored, err := AddEntry(db, "OS", map[string]float64{"c": math.NaN()}); err != nil || !stored.IsZero() {
+		t.Errorf("AddEntry of NaN = %v, %v, want nothing stored", stored, err)
+	}
+
+	if _, err := AddEntry(db, "MISSING", map[string]float64{"c": 1}); err == nil {
+		t.Error("AddEntry into a missing table did not fail")
+	}
+}
+
+func TestSaveVariables(t *testing.T) {
+	tests := []struct {
+		name  string
+		saved []StoredVariable
+		new   []StoredVariable
+		want  []string
+	}{
+		{
+			name: "first session",
+			new:  []StoredVariable{{Table: "OS", Code: "c"}, {Table: "SAMPLES", Code: "1"}},
+			want: []string{"OS c", "SAMPLES 1"},
+		},
+		{
+			name:  "tables of older sessions are kept",
+			saved: []StoredVariable{{Table: "SAMPLES/dev1", Code: "1@dev1"}},
+			new:   []StoredVariable{{Table: "OS", Code: "c"}},
+			want:  []string{"OS c", "SAMPLES/dev1 1@dev1"},
+		},
+		{
+			name:  "same table is replaced",
+			saved: []StoredVariable{{Table: "SAMPLES", Code: "1"}, {Table: "SAMPLES", Code: "2"}},
+			new:   []StoredVariable{{Table: "SAMPLES", Code: "t"}},
+			want:  []string{"SAMPLES t"},
+		},
+		{
+			name:  "same code is replaced",
+			saved: []StoredVariable{{Table: "OLD", Code: "t"}},
+			new:   []StoredVariable{{Table: "SAMPLES", Code: "t"}},
+			want:  []string{"SAMPLES t"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+
+			if variables, err := GetVariables(db); err != nil || len(variables) != 0 {
+				t.Fatalf("GetVariables of a new database = %v, %v", variables, err)
+			}
+
+			for _, variables := range [][]StoredVariable{tt.saved, tt.new} {
+				if err := SaveVariables(db, variables); err != nil {
+					t.Fatalf("SaveVariables error = %v", err)
+				}
+			}
+
+			variables, err := GetVariables(db)
+
+			if err != nil {
+				t.Fatalf("GetVariables error = %v", err)
+			}
+
+			if len(variables) != len(tt.want) {
+				t.Fatalf("got %v, want %v", variables, tt.want)
+			}
+			for i, v := range variables {
+				if got := v.Table + " " + v.Code; got != tt.want[i] {
+					t.Errorf("variable %d = %s, want %s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
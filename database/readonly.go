@@ -0,0 +1,168 @@
+/*
+	Date	:	18/10/2026
+	File	:	readonly.go
+	Overview: 	Readonly opens a database only to query it, so scripts can read
+				the data while a collector keeps writing. Bolt does not share
+				the file with a writer, so in that case a snapshot copy of the
+				file is opened instead.
+*/
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// snapshotPrefix - prefix of the temporary copies opened by OpenReadOnly
+const snapshotPrefix = "ubiSnapshot-"
+
+// Snapshots are taken again, after snapshotRetry, when the file changed while it
+// was copied. snapshotHead covers both meta pages for pages of up to 32KB.
+const (
+	snapshotAttempts = 5
+	snapshotRetry    = 50 * time.Millisecond
+	snapshotHead     = 64 << 10
+)
+
+// OpenReadOnly - Open database nameDB only for reading. When another process
+// holds the database (e.g. a running collector), a snapshot copy of the file is
+// opened instead. Close it with CloseReadOnly.
+func OpenReadOnly(nameDB string) (*bolt.DB, error) {
+	// Opening an unknown file would create it
+	if _, err := os.Stat(nameDB + ".db"); err != nil {
+		return nil, fmt.Errorf("[Database] - Error opening %s.db: %v", nameDB, err)
+	}
+
+	db, err := bolt.Open(nameDB+".db", 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+
+	// Database is locked by a writer, so read a copy of it
+	if err == bolt.ErrTimeout {
+		db, err = openSnapshot(nameDB + ".db")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("[Database] - Error opening %s.db: %v", nameDB, err)
+	}
+
+	// Queries only understand binary keys, which SetupDB migrates to
+	err = db.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket([]byte("DB"))
+
+		if root == nil || string(root.Get([]byte("VERSION"))) != SchemaVersion {
+			return fmt.Errorf("[Database] - %s.db is not up to date, run the collector once to migrate it", nameDB)
+		}
+		return nil
+	})
+
+	if err != nil {
+		CloseReadOnly(db)
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// CloseReadOnly - Close a database opened by OpenReadOnly, removing its snapshot
+// copy if there is one
+func CloseReadOnly(db *bolt.DB) error {
+	path := db.Path()
+	err := db.Close()
+
+	if strings.HasPrefix(path, os.TempDir()) && strings.Contains(path, snapshotPrefix) {
+		os.Remove(path)
+	}
+
+	return err
+}
+
+// openSnapshot - Copy file path into a temporary file and open the copy for
+// reading. Copies taken while a commit was written are thrown away and taken
+// again, up to snapshotAttempts times.
+func openSnapshot(path string) (*bolt.DB, error) {
+	var err error
+
+	for attempt := 1; attempt <= snapshotAttempts; attempt++ {
+		var db *bolt.DB
+
+		if db, err = copySnapshot(path); err == nil {
+			return db, nil
+		}
+
+		time.Sleep(snapshotRetry)
+	}
+
+	return nil, fmt.Errorf("[Database] - No consistent snapshot after %d attempts: %v", snapshotAttempts, err)
+}
+
+// copySnapshot - Copy file path into a temporary file and open the copy. Bolt
+// writes a meta page at the head of the file on every commit, so the copy is
+// consistent when the head did not change while copying.
+func copySnapshot(path string) (*bolt.DB, error) {
+	source, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	before, err := readHead(source)
+
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := ioutil.TempFile("", snapshotPrefix+"*.db")
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(snapshot, source)
+	snapshot.Close()
+
+	if err != nil {
+		os.Remove(snapshot.Name())
+		return nil, err
+	}
+
+	after, err := readHead(source)
+
+	if err == nil && !bytes.Equal(before, after) {
+		err = fmt.Errorf("%s was written while copying it", path)
+	}
+
+	if err != nil {
+		os.Remove(snapshot.Name())
+		return nil, err
+	}
+
+	db, err := bolt.Open(snapshot.Name(), 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+
+	if err != nil {
+		os.Remove(snapshot.Name())
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// readHead - Return the first snapshotHead bytes of file, or all of them when
+// the file is smaller
+func readHead(file *os.File) ([]byte, error) {
+	head := make([]byte, snapshotHead)
+	n, err := file.ReadAt(head, 0)
+
+	if err == io.EOF {
+		err = nil
+	}
+
+	return head[:n], err
+}
@@ -0,0 +1,265 @@
+/*
+	Date	:	18/10/2026
+	File	:	retention.go
+	Overview: 	Retention provides the limits kept for every table (max age
+				and max size) and the functions to prune entries that exceed
+				them, in small batches so writers are never blocked for long.
+*/
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// PruneBatch - maximum number of entries deleted in a single transaction
+const PruneBatch = 1000
+
+// Retention type - limits kept for a table. A zero value means no limit.
+// MaxSize counts the bytes of keys and values of the table entries.
+type Retention struct {
+	MaxAge  time.Duration
+	MaxSize int64
+}
+
+// Prune - Delete entries of table name (inside root bucket) older than MaxAge
+// and, after that, the oldest entries while the table is bigger than MaxSize.
+// Returns the number of entries deleted. Sub-tables (e.g. SAMPLES/greenhouse)
+// are pruned with the same limits, each one on its own.
+func Prune(db *bolt.DB, name string, retention Retention, now time.Time) (int, error) {
+	pruned := 0
+
+	subTables, err := SubTables(db, name)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, subTable := range subTables {
+		deleted, err := Prune(db, subTable, retention, now)
+		pruned = pruned + deleted
+
+		if err != nil {
+			return pruned, err
+		}
+	}
+
+	// Delete expired entries, one batch per transaction
+	if retention.MaxAge > 0 {
+		limitKey := TimeKey(now.Add(-retention.MaxAge))
+
+		for {
+			deleted, err := pruneBatch(db, name, func(k, v []byte) bool {
+				return bytes.Compare(k, limitKey) < 0
+			})
+			pruned = pruned + deleted
+
+			if err != nil {
+				return pruned, err
+			}
+
+			if deleted < PruneBatch {
+				break
+			}
+		}
+	}
+
+	// Delete oldest entries until the table fits in MaxSize
+	if retention.MaxSize > 0 {
+		size, err := tableSize(db, name)
+
+		if err != nil {
+			return pruned, err
+		}
+
+		for excess := size - retention.MaxSize; excess > 0; {
+			deleted, err := pruneBatch(db, name, func(k, v []byte) bool {
+				if excess <= 0 {
+					return false
+				}
+				excess = excess - int64(len(k)+len(v))
+				return true
+			})
+			pruned = pruned + deleted
+
+			if err != nil {
+				return pruned, err
+			}
+
+			if deleted == 0 {
+				break
+			}
+		}
+	}
+
+	return pruned, nil
+}
+
+// pruneBatch - Delete, in a single transaction, up to PruneBatch of the oldest
+// entries of table name while expired returns true for them
+func pruneBatch(db *bolt.DB, name string, expired func(k, v []byte) bool) (int, error) {
+	deleted := 0
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		table := Table(tx, name)
+
+		if table == nil {
+			return fmt.Errorf("[Database] - There is no %s bucket", name)
+		}
+
+		// Collect keys first, since a bucket cant be changed while iterating it
+		var keys [][]byte
+
+		cursor := table.Cursor()
+		for k, v := cursor.First(); k != nil && len(keys) < PruneBatch; k, v = cursor.Next() {
+			// Nested buckets are not entries
+			if v == nil {
+				continue
+			}
+
+			if !expired(k, v) {
+				break
+			}
+			keys = append(keys, append([]byte(nil), k...))
+		}
+
+		for _, k := range keys {
+			if err := table.Delete(k); err != nil {
+				return fmt.Errorf("[Database] - Error pruning %s bucket: %v", name, err)
+			}
+			deleted++
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// tableSize - Return the bytes of keys and values of the entries of table name
+func tableSize(db *bolt.DB, name string) (int64, error) {
+	var size int64
+
+	err := db.View(func(tx *bolt.Tx) error {
+		table := Table(tx, name)
+
+		if table == nil {
+			return fmt.Errorf("[Database] - There is no %s bucket", name)
+		}
+
+		return table.ForEach(func(k, v []byte) error {
+			// Sub-tables count on their own
+			if v == nil {
+				return nil
+			}
+
+			size = size + int64(len(k)+len(v))
+			return nil
+		})
+	})
+
+	return size, err
+}
+
+// ParseRetention - Build the retention of every table from two lists like
+// "OS=72h,SAMPLES=24h" (max ages) and "OS=50MB,SAMPLES=10MB" (max sizes).
+// Sizes accept B, KB, MB and GB suffixes (powers of 1024).
+func ParseRetention(ages string, sizes string) (map[string]Retention, error) {
+	retention := make(map[string]Retention)
+
+	for _, entry := range splitRetention(ages) {
+		name, value, err := splitEntry(entry)
+
+		if err != nil {
+			return nil, err
+		}
+
+		age, err := time.ParseDuration(value)
+
+		if err != nil {
+			return nil, fmt.Errorf("[Database] - Invalid max age %q for %s: %v", value, name, err)
+		}
+
+		r := retention[name]
+		r.MaxAge = age
+		retention[name] = r
+	}
+
+	for _, entry := range splitRetention(sizes) {
+		name, value, err := splitEntry(entry)
+
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := parseSize(value)
+
+		if err != nil {
+			return nil, fmt.Errorf("[Database] - Invalid max size %q for %s: %v", value, name, err)
+		}
+
+		r := retention[name]
+		r.MaxSize = size
+		retention[name] = r
+	}
+
+	return retention, nil
+}
+
+// splitRetention - Split a retention list, ignoring empty entries
+func splitRetention(list string) []string {
+	var entries []string
+
+	for _, entry := range strings.Split(list, ",") {
+		if strings.TrimSpace(entry) != "" {
+			entries = append(entries, strings.TrimSpace(entry))
+		}
+	}
+
+	return entries
+}
+
+// splitEntry - Split an entry like OS=72h into table name and value
+func splitEntry(entry string) (string, string, error) {
+	parts := strings.SplitN(entry, "=", 2)
+
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("[Database] - Invalid retention %q, use TABLE=value", entry)
+	}
+
+	return strings.ToUpper(parts[0]), parts[1], nil
+}
+
+// parseSize - Parse a size like 512, 64KB, 50MB or 1GB into bytes
+func parseSize(value string) (int64, error) {
+	units := []struct {
+		suffix string
+		bytes  int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			n, err := strconv.ParseInt(strings.TrimSuffix(value, unit.suffix), 10, 64)
+			return n * unit.bytes, err
+		}
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
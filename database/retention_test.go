@@ -0,0 +1,182 @@
+/*
+	Date	:	18/10/2026
+	File	:	retention_test.go
+	Overview: 	Tests of the pruning of tables by age and size and of the
+				parsing of retention limits.
+*/
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// addAt - Store an entry {"c":1} in table at every time, 15 bytes each
+func addAt(t *testing.T, db *bolt.DB, table string, times ...time.Time) {
+	t.Helper()
+
+	var entries []Entry
+
+	for _, at := range times {
+		entries = append(entries, Entry{Time: at, Values: map[string]float64{"c": 1}})
+	}
+
+	if err := AddEntries(db, table, entries); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+}
+
+// countEntries - Return the number of entries of table, without its sub-tables
+func countEntries(t *testing.T, db *bolt.DB, table string) int {
+	t.Helper()
+
+	count := 0
+
+	db.View(func(tx *bolt.Tx) error {
+		return Table(tx, table).ForEach(func(k, v []byte) error {
+			if v != nil {
+				count++
+			}
+			return nil
+		})
+	})
+
+	return count
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	// Entries 3h, 2h, 1h30m and 30m old, in SAMPLES and SAMPLES/dev1
+	ages := []time.Duration{3 * time.Hour, 2 * time.Hour, 90 * time.Minute, 30 * time.Minute}
+
+	tests := []struct {
+		name      string
+		retention Retention
+		pruned    int
+		left      int
+	}{
+		{name: "no limits", retention: Retention{}, pruned: 0, left: 4},
+		{name: "max age", retention: Retention{MaxAge: time.Hour}, pruned: 6, left: 1},
+		{name: "max size", retention: Retention{MaxSize: 30}, pruned: 4, left: 2},
+		{name: "max age and size", retention: Retention{MaxAge: 150 * time.Minute, MaxSize: 15}, pruned: 6, left: 1},
+		{name: "limits above the data", retention: Retention{MaxAge: 24 * time.Hour, MaxSize: 1 << 20}, pruned: 0, left: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+
+			if err := CreateTable(db, SubTable("SAMPLES", "dev1")); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+
+			for _, age := range ages {
+				addAt(t, db, "SAMPLES", now.Add(-age))
+				addAt(t, db, SubTable("SAMPLES", "dev1"), now.Add(-age))
+			}
+
+			pruned, err := Prune(db, "SAMPLES", tt.retention, now)
+
+			if err != nil {
+				t.Fatalf("Prune error = %v", err)
+			}
+			if pruned != tt.pruned {
+				t.Errorf("pruned = %d, want %d", pruned, tt.pruned)
+			}
+
+			// Sub-tables are pruned with the same limits
+			for _, table := range []string{"SAMPLES", SubTable("SAMPLES", "dev1")} {
+				if left := countEntries(t, db, table); left != tt.left {
+					t.Errorf("%s has %d entries, want %d", table, left, tt.left)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneBatches(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	db := openTestDB(t)
+
+	// More expired entries than a single batch deletes
+	var times []time.Time
+
+	for i := 0; i < 2*PruneBatch+10; i++ {
+		times = append(times, now.Add(-time.Hour-time.Duration(i)*time.Second))
+	}
+	addAt(t, db, "OS", times...)
+	addAt(t, db, "OS", now)
+
+	pruned, err := Prune(db, "OS", Retention{MaxAge: time.Minute}, now)
+
+	if err != nil {
+		t.Fatalf("Prune error = %v", err)
+	}
+	if pruned != len(times) {
+		t.Errorf("pruned = %d, want %d", pruned, len(times))
+	}
+	if left := countEntries(t, db, "OS"); left != 1 {
+		t.Errorf("OS has %d entries, want 1", left)
+	}
+}
+
+func TestPruneUnknownTable(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := Prune(db, "MISSING", Retention{MaxAge: time.Hour}, time.Now()); err == nil {
+		t.Error("Prune of a missing table did not fail")
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		name    string
+		ages    string
+		sizes   string
+		want    map[string]Retention
+		wantErr bool
+	}{
+		{name: "empty", want: map[string]Retention{}},
+		{
+			name:  "ages and sizes",
+			ages:  "OS=72h, samples=24h",
+			sizes: "OS=50MB,SAMPLES=512",
+			want:  map[string]Retention{"OS": {MaxAge: 72 * time.Hour, MaxSize: 50 << 20}, "SAMPLES": {MaxAge: 24 * time.Hour, MaxSize: 512}},
+		},
+		{
+			name:  "size units",
+			sizes: "A=1B,B=2kb,C=3GB",
+			want:  map[string]Retention{"A": {MaxSize: 1}, "B": {MaxSize: 2 << 10}, "C": {MaxSize: 3 << 30}},
+		},
+		{name: "missing table", ages: "=1h", wantErr: true},
+		{name: "missing value", ages: "OS", wantErr: true},
+		{name: "invalid age", ages: "OS=soon", wantErr: true},
+		{name: "invalid size", sizes: "OS=big", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRetention(tt.ages, tt.sizes)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRetention error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d tables, want %d", len(got), len(tt.want))
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("%s = %+v, want %+v", name, got[name], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+/*
+	Date	:	18/10/2026
+	File	:	rollup.go
+	Overview: 	Rollup provides the summary tables kept beside every raw table
+				(OS_1M, OS_1H, SAMPLES_1M, ...). Each entry holds min, max, avg
+				and count of every variable inside a fixed window, so months
+				of history can be kept while raw data expires after days.
+*/
+
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// RollupBatch - maximum number of windows written in a single transaction
+const RollupBatch = 1000
+
+// RollupDelay - time waited after a window ends before rolling it up, so points
+// written late by the tickers still make it into their window
+const RollupDelay = 5 * time.Second
+
+// RollupTier type - rollup tables with a fixed window width
+type RollupTier struct {
+	Suffix string
+	Width  time.Duration
+}
+
+// RollupTiers - tiers kept for every raw table, finest first. Every tier is
+// built from the previous one (raw data for the first tier).
+var RollupTiers = []RollupTier{
+	{Suffix: "1M", Width: time.Minute},
+	{Suffix: "1H", Width: time.Hour},
+}
+
+// Rollup type - summary of the values of one variable inside a window
+type Rollup struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Avg   float64 `json:"avg"`
+	Count int     `json:"count"`
+}
+
+// Merge - Combine two rollups of the same variable into one
+func (r Rollup) Merge(other Rollup) Rollup {
+	if r.Count == 0 {
+		return other
+	}
+	if other.Count == 0 {
+		return r
+	}
+
+	merged := Rollup{Min: r.Min, Max: r.Max, Count: r.Count + other.Count}
+
+	if other.Min < merged.Min {
+		merged.Min = other.Min
+	}
+	if other.Max > merged.Max {
+		merged.Max = other.Max
+	}
+
+	// Average weighted by the number of values of each rollup
+	merged.Avg = (r.Avg*float64(r.Count) + other.Avg*float64(other.Count)) / float64(merged.Count)
+
+	return merged
+}
+
+// RollupTable - Return the name of the table with the rollups of table name.
+// Rollups of a sub-table are kept in the same sub-table of the parent rollup
+// table, e.g. SAMPLES/greenhouse is rolled up into SAMPLES_1M/greenhouse.
+func RollupTable(name string, tier RollupTier) string {
+	parts := strings.SplitN(name, SubTableSeparator, 2)
+	parts[0] = parts[0] + "_" + tier.Suffix
+
+	return strings.Join(parts, SubTableSeparator)
+}
+
+// rollupTables - Return the names of every rollup table of table name
+func rollupTables(name string) []string {
+	var tables []string
+
+	for _, tier := range RollupTiers {
+		tables = append(tables, RollupTable(name, tier))
+	}
+
+	return tables
+}
+
+// DecodeRollups - Decode a json value of a raw or rollup table into a rollup per
+// variable key. A raw value becomes a rollup of a single value.
+func DecodeRollups(value []byte) (map[string]Rollup, error) {
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return nil, err
+	}
+
+	rollups := make(map[string]Rollup)
+
+	for key, field := range fields {
+		// Rollup tables store objects, raw tables store numbers
+		if len(field) > 0 && field[0] == '{' {
+			var r Rollup
+
+			if err := json.Unmarshal(field, &r); err != nil {
+				return nil, err
+			}
+			rollups[key] = r
+			continue
+		}
+
+		var v float64
+
+		// Fields that are not numbers are not variables
+		if err := json.Unmarshal(field, &v); err != nil {
+			continue
+		}
+		rollups[key] = Rollup{Min: v, Max: v, Avg: v, Count: 1}
+	}
+
+	return rollups, nil
+}
+
+// RollUp - Aggregate every complete window of table source not rolled up yet into
+// table target, one entry per window keyed by the window start. It resumes after
+// the last window found in target and returns the number of windows written.
+func RollUp(db *bolt.DB, source string, target string, width time.Duration, now time.Time) (int, error) {
+	written := 0
+
+	// Only windows that ended some time ago are complete
+	limit := now.Add(-RollupDelay).Truncate(width)
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		sourceTable := Table(tx, source)
+		targetTable := Table(tx, target)
+
+		if sourceTable == nil || targetTable == nil {
+			return fmt.Errorf("[Database] - There is no %s or %s bucket", source, target)
+		}
+
+		cursor := sourceTable.Cursor()
+		k, v := cursor.First()
+
+		// Resume after the last window written
+		if last, _ := targetTable.Cursor().Last(); last != nil {
+			k, v = cursor.Seek(TimeKey(KeyTime(last).Add(width)))
+		}
+
+		var windowStart time.Time
+		window := make(map[string]Rollup)
+
+		// Write window being built into target table
+		flush := func() error {
+			if len(window) == 0 {
+				return nil
+			}
+
+			windowBytes, err := json.Marshal(window)
+
+			if err != nil {
+				return fmt.Errorf("[Database] - Error encoding rollup data: %v", err)
+			}
+
+			if err := targetTable.Put(TimeKey(windowStart), windowBytes); err != nil {
+				return fmt.Errorf("[Database] - Error inserting data to %s bucket: %v", target, err)
+			}
+
+			written++
+			window = make(map[string]Rollup)
+			return nil
+		}
+
+		for ; k != nil; k, v = cursor.Next() {
+			// Nested buckets are not entries
+			if v == nil {
+				continue
+			}
+
+			t := KeyTime(k)
+
+			if !t.Before(limit) {
+				break
+			}
+
+			// A new window starts, write the previous one
+			if start := t.Truncate(width); !start.Equal(windowStart) {
+				if err := flush(); err != nil {
+					return err
+				}
+
+				if written >= RollupBatch {
+					break
+				}
+				windowStart = start
+			}
+
+			rollups, err := DecodeRollups(v)
+
+			// Entries that cant be decoded are skipped
+			if err != nil {
+				continue
+			}
+
+			for key, r := range rollups {
+				window[key] = window[key].Merge(r)
+			}
+		}
+
+		return flush()
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return written, nil
+}
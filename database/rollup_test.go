@@ -0,0 +1,229 @@
+/*
+	Date	:	18/10/2026
+	File	:	rollup_test.go
+	Overview: 	Tests of the rollup tables and of the windows written into
+				them.
+*/
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// readRollups - Return the rollups of table, by window start
+func readRollups(t *testing.T, db *bolt.DB, table string) map[time.Time]map[string]Rollup {
+	t.Helper()
+
+	windows := make(map[time.Time]map[string]Rollup)
+
+	db.View(func(tx *bolt.Tx) error {
+		return Table(tx, table).ForEach(func(k, v []byte) error {
+			rollups, err := DecodeRollups(v)
+
+			if err != nil {
+				t.Fatalf("decode %s: %v", table, err)
+			}
+			windows[KeyTime(k).UTC()] = rollups
+			return nil
+		})
+	})
+
+	return windows
+}
+
+func TestRollupMerge(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Rollup
+		b    Rollup
+		want Rollup
+	}{
+		{"empty with rollup", Rollup{}, Rollup{Min: 1, Max: 3, Avg: 2, Count: 2}, Rollup{Min: 1, Max: 3, Avg: 2, Count: 2}},
+		{"rollup with empty", Rollup{Min: 1, Max: 3, Avg: 2, Count: 2}, Rollup{}, Rollup{Min: 1, Max: 3, Avg: 2, Count: 2}},
+		{"weighted average", Rollup{Min: 1, Max: 3, Avg: 2, Count: 3}, Rollup{Min: 0, Max: 10, Avg: 6, Count: 1}, Rollup{Min: 0, Max: 10, Avg: 3, Count: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Merge(tt.b); got != tt.want {
+				t.Errorf("Merge = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollupTable(t *testing.T) {
+	tests := []struct {
+		name string
+		tier RollupTier
+		want string
+	}{
+		{"OS", RollupTiers[0], "OS_1M"},
+		{"SAMPLES", RollupTiers[1], "SAMPLES_1H"},
+		{SubTable("SAMPLES", "dev1"), RollupTiers[0], "SAMPLES_1M/dev1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RollupTable(tt.name, tt.tier); got != tt.want {
+				t.Errorf("RollupTable(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRollups(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]Rollup
+		wantErr bool
+	}{
+		{name: "raw values", value: `{"c":2,"r":4}`, want: map[string]Rollup{"c": {Min: 2, Max: 2, Avg: 2, Count: 1}, "r": {Min: 4, Max: 4, Avg: 4, Count: 1}}},
+		{name: "rollup values", value: `{"c":{"min":1,"max":3,"avg":2,"count":5}}`, want: map[string]Rollup{"c": {Min: 1, Max: 3, Avg: 2, Count: 5}}},
+		{name: "fields that are not numbers", value: `{"c":1,"device":"dev1"}`, want: map[string]Rollup{"c": {Min: 1, Max: 1, Avg: 1, Count: 1}}},
+		{name: "not json", value: `c=1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeRollups([]byte(tt.value))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeRollups error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d rollups, want %d", len(got), len(tt.want))
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s = %+v, want %+v", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRollUp(t *testing.T) {
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	// Values of c at seconds since base
+	type point struct {
+		at    int
+		value float64
+	}
+
+	tests := []struct {
+		name    string
+		points  []point
+		now     time.Time
+		written int
+		want    map[time.Time]Rollup
+	}{
+		{
+			name:    "complete windows",
+			points:  []point{{0, 1}, {30, 3}, {60, 10}, {90, 20}},
+			now:     base.Add(3 * time.Minute),
+			written: 2,
+			want:    map[time.Time]Rollup{base: {Min: 1, Max: 3, Avg: 2, Count: 2}, base.Add(time.Minute): {Min: 10, Max: 20, Avg: 15, Count: 2}},
+		},
+		{
+			name:    "window not complete yet",
+			points:  []point{{0, 1}, {60, 10}},
+			now:     base.Add(time.Minute + RollupDelay + 30*time.Second),
+			written: 1,
+			want:    map[time.Time]Rollup{base: {Min: 1, Max: 1, Avg: 1, Count: 1}},
+		},
+		{
+			name:    "window ended within the delay",
+			points:  []point{{0, 1}},
+			now:     base.Add(time.Minute + RollupDelay/2),
+			written: 0,
+			want:    map[time.Time]Rollup{},
+		},
+		{
+			name:    "empty windows are skipped",
+			points:  []point{{0, 1}, {150, 5}},
+			now:     base.Add(5 * time.Minute),
+			written: 2,
+			want:    map[time.Time]Rollup{base: {Min: 1, Max: 1, Avg: 1, Count: 1}, base.Add(2 * time.Minute): {Min: 5, Max: 5, Avg: 5, Count: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+
+			if err := CreateTable(db, "OS"); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+
+			var entries []Entry
+
+			for _, p := range tt.points {
+				entries = append(entries, Entry{Time: base.Add(time.Duration(p.at) * time.Second), Values: map[string]float64{"c": p.value}})
+			}
+
+			if err := AddEntries(db, "OS", entries); err != nil {
+				t.Fatalf("add: %v", err)
+			}
+
+			written, err := RollUp(db, "OS", "OS_1M", time.Minute, tt.now)
+
+			if err != nil {
+				t.Fatalf("RollUp error = %v", err)
+			}
+			if written != tt.written {
+				t.Errorf("written = %d, want %d", written, tt.written)
+			}
+
+			windows := readRollups(t, db, "OS_1M")
+
+			if len(windows) != len(tt.want) {
+				t.Errorf("got %d windows, want %d", len(windows), len(tt.want))
+			}
+			for start, want := range tt.want {
+				if windows[start]["c"] != want {
+					t.Errorf("window %v = %+v, want %+v", start, windows[start]["c"], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRollUpResumes(t *testing.T) {
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	db := openTestDB(t)
+
+	if err := CreateTable(db, "OS"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	addAt(t, db, "OS", base, base.Add(time.Minute))
+
+	// First window only, then the second one on the next run
+	for i, want := range []int{1, 1, 0} {
+		written, err := RollUp(db, "OS", "OS_1M", time.Minute, base.Add(time.Duration(i+1)*time.Minute+RollupDelay))
+
+		if err != nil {
+			t.Fatalf("RollUp error = %v", err)
+		}
+		if written != want {
+			t.Errorf("run %d written = %d, want %d", i, written, want)
+		}
+	}
+
+	// Hourly tier is built from the minute one
+	if written, _ := RollUp(db, "OS_1M", "OS_1H", time.Hour, base.Add(2*time.Hour)); written != 1 {
+		t.Errorf("hourly written = %d, want 1", written)
+	}
+
+	if got := readRollups(t, db, "OS_1H")[base]["c"]; got != (Rollup{Min: 1, Max: 1, Avg: 1, Count: 2}) {
+		t.Errorf("hourly window = %+v", got)
+	}
+}
@@ -10,47 +10,87 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/api"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
 	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/sim"
 	"github.com/itsMeDacarvalho/ubiwhere-challenge/toolset"
 
 	"github.com/boltdb/bolt"
 )
 
 func main() {
-	//Setup a new database passing name as argument
-	db, err := database.SetupDB("ubiDB")
+	// Non interactive subcommands, see commands.go
+	if len(os.Args) > 1 && runCommand(os.Args[1], os.Args[2:]) {
+		return
+	}
 
-	// Create new section into log file
-	toolset.WriteToLog("+----------------------------------------+\n")
-	toolset.WriteToLog(fmt.Sprintf("| Session: %s - %s \t\t | \n", toolset.GetFormatedDate(), toolset.GetFormatedTime()))
-	toolset.WriteToLog("+----------------------------------------+\n")
-	toolset.WriteToLog(fmt.Sprintf("Time \t\t || Source \t Message\n\n"))
+	// Command line flags to query a time range without the menu
+	fromFlag := flag.String("from", "", "print metrics stored from this instant (yy/mm/dd hh:mm:ss or hh:mm:ss) and exit")
+	toFlag := flag.String("to", "", "end of the range given by -from (default now)")
+	varsFlag := flag.String("vars", "", "variables to print with -from, e.g. c,r,1 (default every variable but disk, interface and process ones)")
+	windowFlag := flag.Duration("window", 0, "group the range given by -from into windows of this size (e.g. 10s, 1m, 1h)")
+	fnFlag := flag.String("fn", "mean", "statistic applied to every window given by -window: count, min, max, sum, mean, stddev, median, p95 or p99. Windows of 1m or more read rollup tables, where stddev, median and percentiles are approximate, computed over the averages of each minute or hour")
+	formatFlag := flag.String("format", "table", "output of -window queries: table or json")
+	retentionAgeFlag := flag.String("retention", "", "max age kept per table, e.g. OS=72h,SAMPLES=24h")
+	retentionSizeFlag := flag.String("retention-size", "", "max size kept per table, e.g. OS=50MB,SAMPLES=10MB")
+	httpFlag := flag.String("http", "", "serve the HTTP API on this address, e.g. :8080")
+	originsFlag := flag.String("origins", "", "other origins of pages allowed to open WebSocket streams, e.g. https://wallboard.local (* for any)")
+	watchFlag := flag.String("watch", "", "processes to watch, by name or pidfile, e.g. gatewayd,/run/app.pid")
+	cgroupFlag := flag.String("cgroup", "auto", "read CPU and RAM from the container cgroup: auto, on or off")
+	flag.DurationVar(&toolset.JoinTolerance, "tolerance", toolset.JoinTolerance, "maximum distance between OS and SAMPLES points shown in the same row")
+	device := deviceFlags(flag.CommandLine)
+	deviceFlag := flag.String("device", "", "only print variables of this fleet device with -from")
+	flag.Parse()
+
+	devices, err := loadDevices(device)
 
-	//Handle error from setupDB
 	if err != nil {
-		toolset.WriteToLog(fmt.Sprintf("%s \t || [Database] \t Cant perform setup: %v \n", toolset.GetFormatedTime(), err))
+		fmt.Printf("[Info] - %v\n", err)
+		return
+	}
+
+	db, err := openSession("ubiDB")
+
+	if err != nil {
+		fmt.Printf("[Info] - %v\n", err)
+		return
 	}
-	//Sucess creating new database
-	toolset.WriteToLog(fmt.Sprintf("%s \t || [Database] \t Init setup performed with success \n", toolset.GetFormatedTime()))
 
 	//Make sure db close at the end of this function
 	defer db.Close()
 
-	//Set DB configuration: only created time
-	config := database.Config{LastAccessTime: time.Now().Format("06/01/02 15:04:05")}
-	err = database.SetConfig(db, config)
+	// Range given as flag: print it, with the variables stored, and leave
+	if *fromFlag != "" {
+		if err := useStored(db); err != nil {
+			fmt.Printf("[Info] - %v\n", err)
+			return
+		}
 
-	//Handle possible setConfig errors
-	if err != nil {
-		toolset.WriteToLog(fmt.Sprintf("%s \t || [Database] \t Something went wrong with configuration: %v \n", toolset.GetFormatedTime(), err))
+		vars, err := allVariables(db, *varsFlag, *deviceFlag)
+
+		if err != nil {
+			fmt.Printf("[Info] - %v\n", err)
+			return
+		}
+
+		printRangeFlags(db, *fromFlag, *toFlag, vars, *windowFlag, *fnFlag, *formatFlag)
+		return
 	}
 
-	go scheduleDataOS(db)
-	go scheduleSampleData(db)
+	err = startBackground(db, devices, *retentionAgeFlag, *retentionSizeFlag, *httpFlag, *originsFlag, *watchFlag, *cgroupFlag)
+
+	if err != nil {
+		fmt.Printf("[Info] - %v\n", err)
+		return
+	}
 
 	// Wait one second to sample the first data
 	time.Sleep(time.Second * 1)
@@ -67,21 +107,67 @@ func main() {
 			os.Exit(0)
 			break
 		case "1":
-			data, _ := toolset.GetLastN(db, value)
+			data, err := toolset.GetLastN(db, value)
 
-			if data != nil {
-				// Print data
-				toolset.PrintLastN(data, value)
+			if err != nil {
+				fmt.Printf("[Info] - %v\n", err)
+				break
 			}
+
+			// Print data
+			toolset.PrintLastN(data)
 		case "2":
-			data, _ := toolset.GetLastN(db, value)
+			data, err := toolset.GetLastN(db, value)
 
-			if data != nil {
-				// Print data
-				toolset.PrintLastN(data, value)
+			if err != nil {
+				fmt.Printf("[Info] - %v\n", err)
+				break
 			}
+
+			// Print data
+			toolset.PrintLastN(data)
 		case "3":
 			toolset.PrintAverage(db, value)
+		case "4":
+			from, to, vars, err := toolset.DecodeRange(value)
+
+			if err != nil {
+				fmt.Printf("[Info] - %v\n", err)
+				break
+			}
+
+			data, err := toolset.GetRange(db, from, to, vars)
+
+			if err != nil {
+				fmt.Printf("[Info] - %v\n", err)
+				break
+			}
+
+			// Print data
+			toolset.PrintLastN(data)
+		case "5":
+			aggregates, _ := toolset.GetAggregate(db, value)
+
+			if aggregates != nil {
+				// Print statistics
+				toolset.PrintAggregate(aggregates)
+			}
+		case "6":
+			span, window, fn, format, vars, err := toolset.DecodeWindow(value)
+
+			if err != nil {
+				fmt.Printf("[Info] - %v\n", err)
+				break
+			}
+
+			windows, err := toolset.GetWindowed(db, time.Now().Add(-span), time.Now(), window, fn, vars)
+
+			if err != nil {
+				fmt.Printf("[Info] - %v\n", err)
+				break
+			}
+
+			toolset.PrintWindows(windows, format)
 		}
 	}
 
@@ -89,26 +175,391 @@ func main() {
 	select {}
 }
 
-// scheduleDataOS - schecule OS data entry every second
-func scheduleDataOS(db *bolt.DB) {
-	toolset.WriteToLog(fmt.Sprintf("%s \t || [OS] \t OS data is now being collected every second \n", toolset.GetFormatedTime()))
+// openSession - setup database nameDB, start a new section into log file and
+// update database configuration
+func openSession(nameDB string) (*bolt.DB, error) {
+	//Setup a new database passing name as argument
+	db, err := database.SetupDB(nameDB)
 
-	// Start new ticker, in order to repeat something every second
-	ticker := time.NewTicker(1 * time.Second)
+	// Create new section into log file
+	toolset.WriteToLog("+----------------------------------------+\n")
+	toolset.WriteToLog(fmt.Sprintf("| Session: %s - %s \t\t | \n", toolset.GetFormatedDate(), toolset.GetFormatedTime()))
+	toolset.WriteToLog("+----------------------------------------+\n")
+	toolset.WriteToLog(fmt.Sprintf("Time \t\t || Source \t Message\n\n"))
 
-	for range ticker.C {
-		toolset.StoreDataOS(db)
+	//Handle error from setupDB, there is no database to work with
+	if err != nil {
+		toolset.WriteToLog(fmt.Sprintf("%s \t || [Database] \t Cant perform setup: %v \n", toolset.GetFormatedTime(), err))
+		return nil, err
+	}
+	//Sucess creating new database
+	toolset.WriteToLog(fmt.Sprintf("%s \t || [Database] \t Init setup performed with success \n", toolset.GetFormatedTime()))
+
+	//Set DB configuration: access time and the machine collecting data
+	host := collector.GetHostInfo()
+	config := database.Config{
+		LastAccessTime: time.Now().Format("06/01/02 15:04:05"),
+		Hostname:       host.Hostname,
+		OS:             host.OS,
+		Platform:       host.Platform,
+		Kernel:         host.Kernel,
+		CPUModel:       host.CPUModel,
+		Cores:          host.Cores,
+	}
+	err = database.SetConfig(db, config)
+
+	//Handle possible setConfig errors
+	if err != nil {
+		toolset.WriteToLog(fmt.Sprintf("%s \t || [Database] \t Something went wrong with configuration: %v \n", toolset.GetFormatedTime(), err))
+	}
+
+	return db, nil
+}
+
+// deviceOptions type - command line flags choosing the simulated devices
+type deviceOptions struct {
+	profile string
+	seed    int64
+	fleet   string
+	replay  string
+	speed   float64
+	every   time.Duration
+	loop    bool
+}
+
+// deviceFlags - define the flags choosing the simulated devices on flags. Queries
+// dont need them, they read the variables stored (see useStored).
+func deviceFlags(flags *flag.FlagSet) *deviceOptions {
+	device := &deviceOptions{}
+
+	flags.StringVar(&device.profile, "profile", "", "profile of the simulated device, .json or .yaml")
+	flags.Int64Var(&device.seed, "seed", 0, "seed of the simulated device, for reproducible values (default seed of the profile or time)")
+	flags.StringVar(&device.fleet, "fleet", "", "fleet of simulated devices, .json or .yaml, instead of a single device")
+	flags.StringVar(&device.replay, "replay", "", "recorded samples to replay instead of the simulated device, .csv or .jsonl")
+	flags.Float64Var(&device.speed, "speed", 1, "pace of -replay, e.g. 10 replays 10 times faster than recorded")
+	flags.DurationVar(&device.every, "every", 0, "replay one recorded sample every interval, whatever their times")
+	flags.BoolVar(&device.loop, "loop", false, "start -replay over when the recording ends")
+
+	return device
+}
+
+// loadDevices - return the device of the profile with seed, when any of them is
+// given (see sim.NewDevice), the devices of a fleet, the replay of a recording, or
+// else the single default device
+func loadDevices(device *deviceOptions) ([]collector.Collector, error) {
+	given := 0
+
+	for _, used := range []bool{device.profile != "" || device.seed != 0, device.fleet != "", device.replay != ""} {
+		if used {
+			given++
+		}
+	}
+
+	if given > 1 {
+		return nil, fmt.Errorf("[Sim] - Use only one of -profile and -seed, -fleet or -replay")
+	}
+
+	switch {
+	case device.fleet != "":
+		devices, err := sim.LoadFleet(device.fleet, nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Every device has its own table, inside SAMPLES
+		var collectors []collector.Collector
+
+		for _, d := range devices {
+			collectors = append(collectors, d)
+		}
+
+		return collectors, nil
+
+	case device.replay != "":
+		recording, err := sim.LoadRecording(device.replay)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []collector.Collector{sim.NewReplay(recording, sim.Pacing{Speed: device.speed, Interval: device.every}, device.loop, nil)}, nil
+	}
+
+	profile, err := loadProfile(device.profile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []collector.Collector{sim.NewDevice(profile, device.seed, nil)}, nil
+}
+
+// saveVariables - save the variables of collectors in database, so they can be
+// queried by processes that do not collect them (see useStored)
+func saveVariables(db *bolt.DB, collectors []collector.Collector) {
+	var variables []database.StoredVariable
+
+	for _, c := range collectors {
+		for _, v := range c.Variables() {
+			variables = append(variables, database.StoredVariable{Table: c.Name(), Code: v.Code, Key: v.Key, Name: v.Name, Unit: v.Unit})
+		}
+	}
+
+	if err := database.SaveVariables(db, variables); err != nil {
+		toolset.WriteToLog(fmt.Sprintf("%s \t || [Database] \t Cant save variables: %v \n", toolset.GetFormatedTime(), err))
+	}
+}
+
+// useStored - register the variables saved in database by the processes that
+// collected it, replacing the collectors of the same tables. Variables whose
+// code is taken by another table are left out.
+func useStored(db *bolt.DB) error {
+	variables, err := database.GetVariables(db)
+
+	if err != nil {
+		return err
+	}
+
+	var tables []string
+	byTable := make(map[string][]collector.Variable)
+
+	for _, v := range variables {
+		if c, _, taken := collector.Lookup(v.Code); taken && c.Name() != v.Table {
+			continue
+		}
+
+		if _, ok := byTable[v.Table]; !ok {
+			tables = append(tables, v.Table)
+		}
+		byTable[v.Table] = append(byTable[v.Table], collector.Variable{Code: v.Code, Key: v.Key, Name: v.Name, Unit: v.Unit})
+	}
+
+	for _, table := range tables {
+		collector.Register(collector.NewStored(table, byTable[table]))
+	}
+
+	return nil
+}
+
+// loadProfile - load the profile at path, or the default one when path is empty
+func loadProfile(path string) (sim.Profile, error) {
+	if path == "" {
+		return sim.DefaultProfile(), nil
+	}
+
+	return sim.LoadProfile(path)
+}
+
+// allVariables - return vars, or every registered variable but labeled ones (see
+// collector.BaseCodes) when vars is empty. With a device, only variables of that
+// fleet device are returned, or an error when db has no table of the device.
+func allVariables(db *bolt.DB, vars string, device string) (string, error) {
+	if device != "" {
+		return toolset.DeviceCodes(db, vars, device)
+	}
+
+	if vars == "" {
+		return collector.BaseCodes(), nil
+	}
+
+	return vars, nil
+}
+
+// startBackground - register and start the collectors of the machine and the
+// simulated devices, rollups and, when given, retention and the HTTP API,
+// streaming to pages of origins besides its own. Every one of them runs on its
+// own goroutine.
+func startBackground(db *bolt.DB, devices []collector.Collector, retentionAge string, retentionSize string, httpAddr string, origins string, watch string, cgroup string) error {
+	// Retention limits given as flags, pruned in background
+	retention, err := database.ParseRetention(retentionAge, retentionSize)
+
+	if err != nil {
+		return err
+	}
+
+	// Collectors of the machine, reading CPU and RAM as given by cgroup mode
+	mode, err := collector.ParseCgroupMode(cgroup)
+
+	if err != nil {
+		return err
+	}
+
+	// Watch list is empty unless processes are given as flag, see NewProcess
+	var watchList []string
+
+	if watch != "" {
+		watchList = strings.Split(watch, ",")
+	}
+
+	machine, err := collector.Machine(mode, watchList)
+
+	if err != nil {
+		return err
+	}
+
+	for _, c := range append(machine, devices...) {
+		collector.Register(c)
+
+		//Create table of every collector
+		if err := database.CreateTable(db, c.Name()); err != nil {
+			toolset.WriteToLog(fmt.Sprintf("%s \t || [Database] \t Cant create %s table: %v \n", toolset.GetFormatedTime(), c.Name(), err))
+		}
+	}
+
+	if osCollector, ok := machine[0].(*collector.OS); ok && osCollector.Cgroup() > 0 {
+		toolset.WriteToLog(fmt.Sprintf("%s \t || [OS] \t CPU and RAM are read from cgroup v%d \n", toolset.GetFormatedTime(), osCollector.Cgroup()))
+	}
+
+	// Queries of other processes find the variables in database
+	saveVariables(db, collector.Collectors())
+
+	if len(retention) > 0 {
+		go scheduleRetention(db, retention)
+	}
+
+	// HTTP API given as flag
+	if httpAddr != "" {
+		if origins != "" {
+			api.AllowedOrigins = strings.Split(origins, ",")
+		}
+
+		go serveAPI(db, httpAddr)
+	}
+
+	go scheduleRollups(db)
+
+	// Every registered collector writes into its own table
+	for _, c := range collector.Collectors() {
+		go scheduleCollector(db, c)
 	}
+
+	return nil
 }
 
-// scheduleDataOS - schecule OS data entry every second
-func scheduleSampleData(db *bolt.DB) {
-	toolset.WriteToLog(fmt.Sprintf("%s \t || [SAMPLE] \t Sample data from simulator is now being collected every second \n", toolset.GetFormatedTime()))
+// scheduleCollector - schedule an entry of collector c every second, or every
+// interval of its own (see collector.Interval)
+func scheduleCollector(db *bolt.DB, c collector.Collector) {
+	interval := collector.Interval(c)
+
+	toolset.WriteToLog(fmt.Sprintf("%s \t || [%s] \t %s data is now being collected every %s \n", toolset.GetFormatedTime(), c.Name(), c.Name(), interval))
 
-	// Start new ticker, in order to repeat something every second
-	ticker := time.NewTicker(1 * time.Second)
+	// Start new ticker, in order to repeat something every interval
+	ticker := time.NewTicker(interval)
 
 	for range ticker.C {
-		toolset.StoreSample(db)
+		toolset.StoreCollector(context.Background(), db, c)
+	}
+}
+
+// serveAPI - serve the HTTP API on address addr until it fails
+func serveAPI(db *bolt.DB, addr string) {
+	toolset.WriteToLog(fmt.Sprintf("%s \t || [API] \t HTTP API is now being served on %s \n", toolset.GetFormatedTime(), addr))
+
+	err := api.ListenAndServe(addr, db)
+
+	toolset.WriteToLog(fmt.Sprintf("%s \t || [API] \t HTTP API stopped: %v \n", toolset.GetFormatedTime(), err))
+}
+
+// scheduleRetention - prune tables that exceed their retention every minute
+func scheduleRetention(db *bolt.DB, retention map[string]database.Retention) {
+	toolset.WriteToLog(fmt.Sprintf("%s \t || [Retention] \t Tables are now being pruned every minute \n", toolset.GetFormatedTime()))
+
+	// Start new ticker, in order to repeat something every minute
+	ticker := time.NewTicker(1 * time.Minute)
+
+	for ; true; <-ticker.C {
+		for name, limits := range retention {
+			pruned, err := database.Prune(db, name, limits, time.Now())
+
+			if err != nil {
+				toolset.WriteToLog(fmt.Sprintf("%s \t || [Retention] \t Error pruning %s table: %v \n", toolset.GetFormatedTime(), name, err))
+			}
+
+			if pruned > 0 {
+				toolset.WriteToLog(fmt.Sprintf("%s \t || [Retention] \t %d entries pruned from %s table \n", toolset.GetFormatedTime(), pruned, name))
+			}
+		}
 	}
 }
+
+// scheduleRollups - roll raw tables up into every rollup tier each minute
+func scheduleRollups(db *bolt.DB) {
+	toolset.WriteToLog(fmt.Sprintf("%s \t || [Rollup] \t Tables are now being rolled up every minute \n", toolset.GetFormatedTime()))
+
+	// Start new ticker, in order to repeat something every minute
+	ticker := time.NewTicker(1 * time.Minute)
+
+	for ; true; <-ticker.C {
+		for _, c := range collector.Collectors() {
+			name := c.Name()
+
+			// Every tier is built from the previous one, starting at the raw table
+			source := name
+
+			for _, tier := range database.RollupTiers {
+				target := database.RollupTable(name, tier)
+
+				// Roll up until there are no complete windows left
+				for {
+					written, err := database.RollUp(db, source, target, tier.Width, time.Now())
+
+					if err != nil {
+						toolset.WriteToLog(fmt.Sprintf("%s \t || [Rollup] \t Error rolling %s up into %s: %v \n", toolset.GetFormatedTime(), source, target, err))
+					}
+
+					if err != nil || written < database.RollupBatch {
+						break
+					}
+				}
+
+				source = target
+			}
+		}
+	}
+}
+
+// printRangeFlags - print metrics between the instants given as command line flags,
+// grouped by windows when a window size is given
+func printRangeFlags(db *bolt.DB, fromStr string, toStr string, vars string, window time.Duration, fn string, format string) {
+	from, err := toolset.ParseTimeArg(fromStr)
+
+	if err != nil {
+		fmt.Printf("[Info] - %v\n", err)
+		return
+	}
+
+	// Without -to the range goes until now
+	to := time.Now()
+
+	if toStr != "" {
+		to, err = toolset.ParseTimeArg(toStr)
+
+		if err != nil {
+			fmt.Printf("[Info] - %v\n", err)
+			return
+		}
+	}
+
+	if window > 0 {
+		windows, err := toolset.GetWindowed(db, from, to, window, fn, vars)
+
+		if err != nil {
+			fmt.Printf("[Info] - %v\n", err)
+			return
+		}
+
+		toolset.PrintWindows(windows, format)
+		return
+	}
+
+	data, err := toolset.GetRange(db, from, to, vars)
+
+	if err != nil {
+		fmt.Printf("[Info] - %v\n", err)
+		return
+	}
+
+	// Print data
+	toolset.PrintLastN(data)
+}
@@ -0,0 +1,185 @@
+/*
+	Date	:	18/10/2026
+	File	:	metrics.go
+	Overview: 	Metrics keeps the latest collected values and some counters of
+				the collectors in memory, and exposes them over HTTP in the
+				Prometheus text exposition format.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Prefix - prefix of every metric name
+const Prefix = "ubiwhere_"
+
+// family type - a metric with every label set seen so far. Summaries keep the
+// sum of observations in values and their number in counts.
+type family struct {
+	kind   string
+	help   string
+	values map[string]float64
+	counts map[string]float64
+}
+
+var (
+	mutex    sync.Mutex
+	families = make(map[string]*family)
+)
+
+// Escapers of HELP text and label values, as the exposition format asks
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// SetGauge - Set gauge name with labels (see Labels) to value
+func SetGauge(name string, help string, labels string, value float64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	getFamily(name, "gauge", help).values[labels] = value
+}
+
+// AddCounter - Add delta to counter name with labels (see Labels)
+func AddCounter(name string, help string, labels string, delta float64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	getFamily(name, "counter", help).values[labels] += delta
+}
+
+// Observe - Add an observation (e.g. a duration in seconds) to summary name
+// with labels (see Labels)
+func Observe(name string, help string, labels string, value float64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	f := getFamily(name, "summary", help)
+	f.values[labels] += value
+	f.counts[labels]++
+}
+
+// Labels - Format label pairs like Labels("table", "OS") into a label set
+func Labels(pairs ...string) string {
+	var labels []string
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, fmt.Sprintf("%s=\"%s\"", pairs[i], labelEscaper.Replace(pairs[i+1])))
+	}
+
+	return strings.Join(labels, ",")
+}
+
+// Name - Build a valid metric name from its parts, like Name("OS", "usedRAM")
+// which gives ubiwhere_os_used_ram
+func Name(parts ...string) string {
+	var name strings.Builder
+
+	name.WriteString(Prefix)
+
+	for i, part := range parts {
+		if i > 0 {
+			name.WriteRune('_')
+		}
+
+		runes := []rune(part)
+
+		for j, r := range runes {
+			// Split camel case words, e.g. usedRAM -> used_ram
+			if unicode.IsUpper(r) && j > 0 && (unicode.IsLower(runes[j-1]) || unicode.IsDigit(runes[j-1])) {
+				name.WriteRune('_')
+			}
+
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				name.WriteRune(unicode.ToLower(r))
+			} else {
+				name.WriteRune('_')
+			}
+		}
+	}
+
+	return name.String()
+}
+
+// Handler - Return an HTTP handler answering every metric in the Prometheus
+// text exposition format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte(Expose()))
+	})
+}
+
+// Expose - Return every metric in the Prometheus text exposition format, sorted
+// by name
+func Expose() string {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var names []string
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+
+	for _, name := range names {
+		f := families[name]
+
+		fmt.Fprintf(&out, "# HELP %s %s\n", name, helpEscaper.Replace(f.help))
+		fmt.Fprintf(&out, "# TYPE %s %s\n", name, f.kind)
+
+		var labelSets []string
+		for labels := range f.values {
+			labelSets = append(labelSets, labels)
+		}
+		sort.Strings(labelSets)
+
+		for _, labels := range labelSets {
+			if f.kind == "summary" {
+				fmt.Fprintf(&out, "%s_sum%s %s\n", name, braces(labels), formatFloat(f.values[labels]))
+				fmt.Fprintf(&out, "%s_count%s %s\n", name, braces(labels), formatFloat(f.counts[labels]))
+			} else {
+				fmt.Fprintf(&out, "%s%s %s\n", name, braces(labels), formatFloat(f.values[labels]))
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// getFamily - Return family name, creating it when needed. Caller holds mutex.
+func getFamily(name string, kind string, help string) *family {
+	f, ok := families[name]
+
+	if !ok {
+		f = &family{kind: kind, help: help, values: make(map[string]float64), counts: make(map[string]float64)}
+		families[name] = f
+	}
+
+	return f
+}
+
+// braces - Wrap a non empty label set in braces
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+
+	return "{" + labels + "}"
+}
+
+// formatFloat - Format a value as expected by Prometheus
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,70 @@
+/*
+	Date	:	18/10/2026
+	File	:	metrics_test.go
+	Overview: 	Tests of the names, labels and HELP text of exposed metrics.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		parts []string
+		want  string
+	}{
+		{[]string{"OS", "usedRAM"}, "ubiwhere_os_used_ram"},
+		{[]string{"writes_total"}, "ubiwhere_writes_total"},
+		{[]string{"SAMPLES/gh1", "t-1"}, "ubiwhere_samples_gh1_t_1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Name(tt.parts...); got != tt.want {
+				t.Errorf("Name(%q) = %s, want %s", tt.parts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		name  string
+		pairs []string
+		want  string
+	}{
+		{"none", nil, ""},
+		{"pairs", []string{"table", "OS", "label", ""}, `table="OS",label=""`},
+		{"escaped", []string{"label", "a\"b\\c\nd"}, `label="a\"b\\c\nd"`},
+		{"utf-8 kept", []string{"label", "estufa-nº1"}, `label="estufa-nº1"`},
+		{"value missing", []string{"table", "OS", "label"}, `table="OS"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Labels(tt.pairs...); got != tt.want {
+				t.Errorf("Labels(%q) = %s, want %s", tt.pairs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExposeHelp(t *testing.T) {
+	name := Name("test_help")
+
+	SetGauge(name, "Two\nlines with a \\ backslash", Labels("table", "OS"), 1)
+	defer func() {
+		mutex.Lock()
+		delete(families, name)
+		mutex.Unlock()
+	}()
+
+	want := "# HELP " + name + " Two\\nlines with a \\\\ backslash\n"
+
+	if got := Expose(); !strings.Contains(got, want) {
+		t.Errorf("Expose() = %s, want it to contain %q", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+	Date	:	18/10/2026
+	File	:	clock.go
+	Overview: 	Clock gives the time seen by a simulated device. The real
+				clock is used while collecting, and a simulated clock, moved
+				forward by hand, generates hours of data in milliseconds.
+*/
+
+package sim
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock interface - source of the current time of a simulated device. Sleep
+// waits for d, or until ctx is done, and is used by stalled devices.
+type Clock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// RealClock type - the time of the machine
+type RealClock struct{}
+
+// Now - Return the time of the machine
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep - Wait for d, or until ctx is done
+func (RealClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SimClock type - a clock that only moves when Advance is called. Sleeps give up
+// at deadline, when set, as a collection given up by its caller.
+type SimClock struct {
+	mutex    sync.Mutex
+	now      time.Time
+	deadline time.Time
+}
+
+// NewSimClock - Return a simulated clock stopped at start
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+// Now - Return the time the clock is stopped at
+func (c *SimClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// Advance - Move the clock forward by d
+func (c *SimClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// SetDeadline - Make sleeps past t stop at t and fail, as the real clock does when
+// the context of a collection times out. A zero t removes the deadline.
+func (c *SimClock) SetDeadline(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.deadline = t
+}
+
+// Sleep - Move the clock forward by d, without waiting, or up to the deadline
+// failing with context.DeadlineExceeded
+func (c *SimClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.deadline.IsZero() && c.now.Add(d).After(c.deadline) {
+		c.now = c.deadline
+		return context.DeadlineExceeded
+	}
+
+	c.now = c.now.Add(d)
+	return nil
+}
@@ -0,0 +1,217 @@
+/*
+	Date	:	18/10/2026
+	File	:	fault.go
+	Overview: 	Fault makes a simulated device misbehave on demand, to test
+				alerting and gap handling: dropped readings, stalls, out of
+				range spikes, stuck values, NaN and disconnections. Faults
+				happen with a probability per reading or at scripted times.
+*/
+
+package sim
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Fault kinds
+const (
+	FaultDrop       = "drop"
+	FaultStall      = "stall"
+	FaultSpike      = "spike"
+	FaultStuck      = "stuck"
+	FaultNaN        = "nan"
+	FaultDisconnect = "disconnect"
+)
+
+// Default durations of faults that last some time
+const (
+	DefaultStall      = 2 * time.Second
+	DefaultStuck      = 30 * time.Second
+	DefaultDisconnect = 10 * time.Second
+)
+
+// Faults type - faults of a device. Drop, Stall, Spike, Stuck, NaN and Disconnect
+// are the probability (0 to 1) of each fault on every reading. Stalls, stuck
+// values and disconnections last StallFor, StuckFor and DisconnectFor.
+type Faults struct {
+	Drop          float64      `json:"drop" yaml:"drop"`
+	Stall         float64      `json:"stall" yaml:"stall"`
+	StallFor      string       `json:"stallFor" yaml:"stallFor"`
+	Spike         float64      `json:"spike" yaml:"spike"`
+	Stuck         float64      `json:"stuck" yaml:"stuck"`
+	StuckFor      string       `json:"stuckFor" yaml:"stuckFor"`
+	NaN           float64      `json:"nan" yaml:"nan"`
+	Disconnect    float64      `json:"disconnect" yaml:"disconnect"`
+	DisconnectFor string       `json:"disconnectFor" yaml:"disconnectFor"`
+	Timeline      []FaultEvent `json:"timeline" yaml:"timeline"`
+
+	durations map[string]time.Duration
+}
+
+// FaultEvent type - a scripted fault, At after the device started. Drops, spikes
+// and NaN hit every reading during For, or only the next one without For. Stalls,
+// stuck values and disconnections start on the next reading and last For (or the
+// duration of Faults when not given).
+type FaultEvent struct {
+	At    string `json:"at" yaml:"at"`
+	Fault string `json:"fault" yaml:"fault"`
+	For   string `json:"for" yaml:"for"`
+
+	at       time.Duration
+	duration time.Duration
+}
+
+// faultState type - faults going on in a device, as elapsed times since it started
+type faultState struct {
+	fired             []bool
+	stuckUntil        time.Duration
+	stuck             []float64
+	disconnectedUntil time.Duration
+}
+
+// Validate - Check probabilities and durations and fill the defaults of the
+// fields not given
+func (f *Faults) Validate() error {
+	probabilities := map[string]float64{FaultDrop: f.Drop, FaultStall: f.Stall, FaultSpike: f.Spike, FaultStuck: f.Stuck, FaultNaN: f.NaN, FaultDisconnect: f.Disconnect}
+
+	for fault, p := range probabilities {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("[Sim] - Probability of %s must be between 0 and 1", fault)
+		}
+	}
+
+	f.durations = make(map[string]time.Duration)
+
+	for fault, value := range map[string]string{FaultStall: f.StallFor, FaultStuck: f.StuckFor, FaultDisconnect: f.DisconnectFor} {
+		d, err := parseFaultDuration(value)
+
+		if err != nil {
+			return fmt.Errorf("[Sim] - Invalid duration %q of %s", value, fault)
+		}
+		f.durations[fault] = d
+	}
+
+	// Faults without a duration given last the default one
+	for fault, d := range map[string]time.Duration{FaultStall: DefaultStall, FaultStuck: DefaultStuck, FaultDisconnect: DefaultDisconnect} {
+		if f.durations[fault] == 0 {
+			f.durations[fault] = d
+		}
+	}
+
+	for i := range f.Timeline {
+		e := &f.Timeline[i]
+
+		if _, ok := probabilities[e.Fault]; !ok {
+			return fmt.Errorf("[Sim] - Unknown fault %q in timeline", e.Fault)
+		}
+
+		at, err := parseFaultDuration(e.At)
+
+		if err != nil {
+			return fmt.Errorf("[Sim] - Invalid time %q of %s in timeline", e.At, e.Fault)
+		}
+
+		duration, err := parseFaultDuration(e.For)
+
+		if err != nil {
+			return fmt.Errorf("[Sim] - Invalid duration %q of %s in timeline", e.For, e.Fault)
+		}
+
+		e.at = at
+		e.duration = duration
+	}
+
+	return nil
+}
+
+// parseFaultDuration - Parse a duration of a fault, where empty means zero
+func parseFaultDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+
+	if err == nil && d < 0 {
+		err = fmt.Errorf("negative duration")
+	}
+
+	return d, err
+}
+
+// triggered - Return the faults hitting the reading at elapsed time, with the
+// duration of the ones that last some time. Probabilities are only rolled for
+// faults that can happen, so devices without faults keep their sequence of values.
+func (f Faults) triggered(rng *rand.Rand, state *faultState, elapsed time.Duration) map[string]time.Duration {
+	faults := make(map[string]time.Duration)
+
+	probabilities := []struct {
+		fault string
+		p     float64
+	}{
+		{FaultDisconnect, f.Disconnect},
+		{FaultStall, f.Stall},
+		{FaultDrop, f.Drop},
+		{FaultStuck, f.Stuck},
+		{FaultSpike, f.Spike},
+		{FaultNaN, f.NaN},
+	}
+
+	// Same order on every reading, so seeded devices always fail the same way
+	for _, p := range probabilities {
+		if p.p > 0 && rng.Float64() < p.p {
+			faults[p.fault] = f.durations[p.fault]
+		}
+	}
+
+	if len(state.fired) != len(f.Timeline) {
+		state.fired = make([]bool, len(f.Timeline))
+	}
+
+	for i, e := range f.Timeline {
+		if elapsed < e.at {
+			continue
+		}
+
+		switch e.Fault {
+		case FaultDrop, FaultSpike, FaultNaN:
+			// Every reading of the window, or the first one after At
+			if elapsed < e.at+e.duration || !state.fired[i] {
+				faults[e.Fault] = 0
+			}
+		default:
+			if !state.fired[i] {
+				faults[e.Fault] = f.durations[e.Fault]
+
+				if e.duration > 0 {
+					faults[e.Fault] = e.duration
+				}
+			}
+		}
+
+		state.fired[i] = true
+	}
+
+	return faults
+}
+
+// spike - Return a value of channel c out of its range, above max or below min
+func (c Channel) spike(rng *rand.Rand) float64 {
+	width := math.Max(c.Max-c.Min, 1)
+
+	value := c.Max + width*(1+rng.Float64())
+
+	if rng.Intn(2) == 0 {
+		value = c.Min - width*(1+rng.Float64())
+	}
+
+	// Ints and bools stay whole numbers
+	if c.Type != TypeFloat {
+		return math.Round(value)
+	}
+
+	return value
+}
@@ -0,0 +1,142 @@
+/*
+	Date	:	18/10/2026
+	File	:	fleet.go
+	Overview: 	Fleet describes several simulated devices, read from a JSON
+				or YAML file. Each device has an ID, a profile, an interval
+				and a seed, and stores its samples into its own sub-table of
+				SAMPLES (e.g. SAMPLES/greenhouse).
+*/
+
+package sim
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// validID - device IDs are used in table names and variable codes, so they
+// cant have separators like / @ or ,
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Fleet type - simulated devices collected at the same time
+type Fleet struct {
+	Devices []FleetDevice `json:"devices" yaml:"devices"`
+}
+
+// FleetDevice type - one or more devices of a fleet. Profile is a profile file,
+// relative to the fleet file, or the default device when empty. With Count above
+// one, devices are called ID1, ID2, ... and device n takes Seed (or the seed of
+// the profile) plus n-1, so every device generates its own values. Faults, when
+// given, replace the faults of the profile.
+type FleetDevice struct {
+	ID       string  `json:"id" yaml:"id"`
+	Profile  string  `json:"profile" yaml:"profile"`
+	Count    int     `json:"count" yaml:"count"`
+	Interval string  `json:"interval" yaml:"interval"`
+	Seed     int64   `json:"seed" yaml:"seed"`
+	Faults   *Faults `json:"faults" yaml:"faults"`
+}
+
+// LoadFleet - Read a fleet from a .json, .yaml or .yml file and return its
+// devices, each one with its profile loaded
+func LoadFleet(path string, clock Clock) ([]*Device, error) {
+	var fleet Fleet
+
+	if err := decodeFile(path, &fleet); err != nil {
+		return nil, err
+	}
+
+	return fleet.NewDevices(filepath.Dir(path), clock)
+}
+
+// NewDevices - Return the devices of the fleet. Profiles are read relative to
+// dir and every device shares clock (nil is the real one).
+func (f Fleet) NewDevices(dir string, clock Clock) ([]*Device, error) {
+	var devices []*Device
+
+	if len(f.Devices) == 0 {
+		return nil, fmt.Errorf("[Sim] - Fleet has no devices")
+	}
+
+	seen := make(map[string]bool)
+
+	for _, fd := range f.Devices {
+		if !validID.MatchString(fd.ID) {
+			return nil, fmt.Errorf("[Sim] - Invalid device ID %q, use letters, digits, _ and -", fd.ID)
+		}
+
+		interval := time.Duration(0)
+
+		if fd.Interval != "" {
+			var err error
+			interval, err = time.ParseDuration(fd.Interval)
+
+			if err != nil || interval <= 0 {
+				return nil, fmt.Errorf("[Sim] - Device %s has invalid interval %q", fd.ID, fd.Interval)
+			}
+		}
+
+		profile := DefaultProfile()
+
+		if fd.Profile != "" {
+			path := fd.Profile
+
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+			var err error
+			profile, err = LoadProfile(path)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if fd.Faults != nil {
+			profile.Faults = *fd.Faults
+
+			if err := profile.Faults.Validate(); err != nil {
+				return nil, err
+			}
+		}
+
+		count := fd.Count
+
+		if count < 1 {
+			count = 1
+		}
+
+		for n := 1; n <= count; n++ {
+			id := fd.ID
+			seed := fd.Seed
+
+			if seed == 0 {
+				seed = profile.Seed
+			}
+
+			if fd.Count > 1 {
+				id = fmt.Sprintf("%s%d", fd.ID, n)
+
+				if seed != 0 {
+					seed = seed + int64(n-1)
+				}
+			}
+
+			if seen[id] {
+				return nil, fmt.Errorf("[Sim] - Device %s is repeated", id)
+			}
+			seen[id] = true
+
+			d := NewDevice(profile, seed, clock)
+			d.id = id
+			d.interval = interval
+
+			devices = append(devices, d)
+		}
+	}
+
+	return devices, nil
+}
@@ -0,0 +1,268 @@
+/*
+	Date	:	18/10/2026
+	File	:	profile.go
+	Overview: 	Profile describes the channels of a simulated device, read
+				from a JSON or YAML file. Each channel has a name, unit, type
+				(int, float or bool), range and the distribution of its values
+				(uniform, gaussian, sine, random walk or step).
+*/
+
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Channel types
+const (
+	TypeInt   = "int"
+	TypeFloat = "float"
+	TypeBool  = "bool"
+)
+
+// Channel distributions
+const (
+	Uniform  = "uniform"
+	Gaussian = "gaussian"
+	Sine     = "sine"
+	Walk     = "walk"
+	Step     = "step"
+)
+
+// Profile type - channels of a simulated device. A non zero Seed makes every
+// run of the device generate the same values, faults included.
+type Profile struct {
+	Name     string    `json:"name" yaml:"name"`
+	Seed     int64     `json:"seed" yaml:"seed"`
+	Channels []Channel `json:"channels" yaml:"channels"`
+	Faults   Faults    `json:"faults" yaml:"faults"`
+}
+
+// Channel type - one value generated by a simulated device. Name is the json key
+// where it is stored and Code what users type to query it (its position, from 1,
+// when not given). Mean and StdDev are used by gaussian, Period by sine and step
+// and StepSize by walk; all of them have defaults taken from the range. Mean is a
+// pointer so a mean of 0 given by the user is told apart from no mean.
+type Channel struct {
+	Name         string   `json:"name" yaml:"name"`
+	Label        string   `json:"label" yaml:"label"`
+	Code         string   `json:"code" yaml:"code"`
+	Unit         string   `json:"unit" yaml:"unit"`
+	Type         string   `json:"type" yaml:"type"`
+	Min          float64  `json:"min" yaml:"min"`
+	Max          float64  `json:"max" yaml:"max"`
+	Distribution string   `json:"distribution" yaml:"distribution"`
+	Mean         *float64 `json:"mean" yaml:"mean"`
+	StdDev       float64  `json:"stddev" yaml:"stddev"`
+	Period       string   `json:"period" yaml:"period"`
+	StepSize     float64  `json:"step" yaml:"step"`
+
+	period time.Duration
+}
+
+// DefaultProfile - the device simulated by older versions: 4 int samples between
+// 0 and 9, stored as sample1 to sample4 with codes 1 to 4
+func DefaultProfile() Profile {
+	profile := Profile{Name: "default"}
+
+	for i := 1; i <= 4; i++ {
+		profile.Channels = append(profile.Channels, Channel{Name: fmt.Sprintf("sample%d", i), Label: fmt.Sprintf("Sample %d", i), Type: TypeInt, Min: 0, Max: 9, Distribution: Uniform})
+	}
+
+	profile.Validate()
+
+	return profile
+}
+
+// LoadProfile - Read a profile from a .json, .yaml or .yml file
+func LoadProfile(path string) (Profile, error) {
+	var profile Profile
+
+	if err := decodeFile(path, &profile); err != nil {
+		return profile, err
+	}
+
+	return profile, profile.Validate()
+}
+
+// decodeFile - Decode a .json, .yaml or .yml file at path into v
+func decodeFile(path string, v interface{}) error {
+	content, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return fmt.Errorf("[Sim] - Error reading %s: %v", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		// Unknown fields are typos, as yaml files are decoded strictly too
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(v)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(content, v)
+	default:
+		return fmt.Errorf("[Sim] - File %s must be .json, .yaml or .yml", path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("[Sim] - Error decoding %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// Validate - Check every channel and fill the defaults of the fields not given
+func (p *Profile) Validate() error {
+	if len(p.Channels) == 0 {
+		return fmt.Errorf("[Sim] - Profile %s has no channels", p.Name)
+	}
+
+	if err := p.Faults.Validate(); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+
+	for i := range p.Channels {
+		c := &p.Channels[i]
+
+		if c.Name == "" {
+			return fmt.Errorf("[Sim] - Channel %d has no name", i+1)
+		}
+
+		if c.Code == "" {
+			c.Code = fmt.Sprint(i + 1)
+		}
+		if c.Label == "" {
+			c.Label = c.Name
+		}
+		if c.Type == "" {
+			c.Type = TypeFloat
+		}
+		if c.Distribution == "" {
+			c.Distribution = Uniform
+		}
+
+		// Codes are typed into code strings, beside the codes of the machine
+		if strings.ContainsAny(c.Code, ","+collector.LabelSeparator+collector.DeviceSeparator) {
+			return fmt.Errorf("[Sim] - Channel %s has code %q, which cant have , %s or %s", c.Name, c.Code, collector.LabelSeparator, collector.DeviceSeparator)
+		}
+		if collector.MachineCode(c.Code) {
+			return fmt.Errorf("[Sim] - Channel %s has code %q, taken by the collectors of the machine", c.Name, c.Code)
+		}
+
+		if seen[c.Name] || seen["code "+c.Code] {
+			return fmt.Errorf("[Sim] - Channel %s or code %s is repeated", c.Name, c.Code)
+		}
+		seen[c.Name] = true
+		seen["code "+c.Code] = true
+
+		if c.Type != TypeInt && c.Type != TypeFloat && c.Type != TypeBool {
+			return fmt.Errorf("[Sim] - Channel %s has unknown type %q", c.Name, c.Type)
+		}
+
+		// Bools are 0 or 1
+		if c.Type == TypeBool && c.Min == 0 && c.Max == 0 {
+			c.Max = 1
+		}
+
+		if c.Max < c.Min {
+			return fmt.Errorf("[Sim] - Channel %s has max below min", c.Name)
+		}
+
+		switch c.Distribution {
+		case Uniform, Walk:
+		case Gaussian:
+			if c.Mean == nil {
+				mean := (c.Min + c.Max) / 2
+				c.Mean = &mean
+			}
+		case Sine, Step:
+			c.period = time.Minute
+
+			if c.Period != "" {
+				period, err := time.ParseDuration(c.Period)
+
+				if err != nil || period <= 0 {
+					return fmt.Errorf("[Sim] - Channel %s has invalid period %q", c.Name, c.Period)
+				}
+				c.period = period
+			}
+		default:
+			return fmt.Errorf("[Sim] - Channel %s has unknown distribution %q", c.Name, c.Distribution)
+		}
+
+		if c.StdDev == 0 {
+			c.StdDev = (c.Max - c.Min) / 6
+		}
+		if c.StepSize == 0 {
+			c.StepSize = (c.Max - c.Min) / 20
+		}
+	}
+
+	return nil
+}
+
+// next - Return the next value of channel c at elapsed time since the device
+// started. previous is the last value, used by random walks.
+func (c Channel) next(rng *rand.Rand, elapsed time.Duration, previous float64) float64 {
+	var value float64
+
+	switch c.Distribution {
+	case Uniform:
+		// Ints take every value of the range with the same chance
+		if c.Type != TypeFloat {
+			return c.Min + float64(rng.Intn(int(c.Max-c.Min)+1))
+		}
+		value = c.Min + rng.Float64()*(c.Max-c.Min)
+	case Gaussian:
+		value = *c.Mean + rng.NormFloat64()*c.StdDev
+	case Sine:
+		value = (c.Min+c.Max)/2 + (c.Max-c.Min)/2*math.Sin(2*math.Pi*elapsed.Seconds()/c.period.Seconds())
+	case Walk:
+		value = previous + rng.NormFloat64()*c.StepSize
+	case Step:
+		// Square wave, half period at min and half at max
+		value = c.Min
+		if elapsed%c.period >= c.period/2 {
+			value = c.Max
+		}
+	}
+
+	return c.convert(value)
+}
+
+// start - Return the first value of a random walk, the middle of the range
+func (c Channel) start() float64 {
+	return c.convert((c.Min + c.Max) / 2)
+}
+
+// convert - Keep value inside the range and convert it to the channel type
+func (c Channel) convert(value float64) float64 {
+	value = math.Max(c.Min, math.Min(c.Max, value))
+
+	switch c.Type {
+	case TypeInt:
+		return math.Round(value)
+	case TypeBool:
+		if value >= (c.Min+c.Max)/2 {
+			return 1
+		}
+		return 0
+	}
+
+	return value
+}
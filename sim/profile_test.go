@@ -0,0 +1,109 @@
+/*
+	Date	:	18/10/2026
+	File	:	profile_test.go
+	Overview: 	Tests of the profiles read from files, their defaults and the
+				codes their channels can take.
+*/
+
+package sim
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantMean float64
+		wantErr  bool
+	}{
+		{
+			name:     "mean of the range by default",
+			file:     "p.json",
+			content:  `{"name":"d","channels":[{"name":"t","min":10,"max":20,"distribution":"gaussian"}]}`,
+			wantMean: 15,
+		},
+		{
+			name:     "mean of 0 is kept",
+			file:     "p.json",
+			content:  `{"name":"d","channels":[{"name":"t","min":-10,"max":20,"distribution":"gaussian","mean":0}]}`,
+			wantMean: 0,
+		},
+		{
+			name:     "yaml mean of 0 is kept",
+			file:     "p.yaml",
+			content:  "name: d\nchannels:\n  - name: t\n    min: -10\n    max: 20\n    distribution: gaussian\n    mean: 0\n",
+			wantMean: 0,
+		},
+		{
+			name:    "unknown json field",
+			file:    "p.json",
+			content: `{"name":"d","channels":[{"name":"t","min":0,"max":1,"distribution":"gaussian","stdev":1}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown yaml field",
+			file:    "p.yaml",
+			content: "name: d\nchannel:\n  - name: t\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+
+			profile, err := LoadProfile(path)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadProfile error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if mean := profile.Channels[0].Mean; mean == nil || *mean != tt.wantMean {
+				t.Errorf("mean = %v, want %v", mean, tt.wantMean)
+			}
+		})
+	}
+}
+
+func TestValidateCodes(t *testing.T) {
+	tests := []struct {
+		code    string
+		wantErr bool
+	}{
+		{code: "", wantErr: false},
+		{code: "t", wantErr: false},
+		{code: "temp1", wantErr: false},
+		{code: "c", wantErr: true},
+		{code: "r", wantErr: true},
+		{code: "c3", wantErr: true},
+		{code: "l15", wantErr: true},
+		{code: "du", wantErr: true},
+		{code: "rxb", wantErr: true},
+		{code: "pc", wantErr: true},
+		{code: "t,h", wantErr: true},
+		{code: "t:eth0", wantErr: true},
+		{code: "t@gh1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			profile := Profile{Name: "d", Channels: []Channel{{Name: "t", Code: tt.code, Max: 1}}}
+
+			if err := profile.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate with code %q error = %v, want error %v", tt.code, err, tt.wantErr)
+			}
+		})
+	}
+}
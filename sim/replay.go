@@ -0,0 +1,381 @@
+/*
+	Date	:	18/10/2026
+	File	:	replay.go
+	Overview: 	Replay feeds recorded samples, read from CSV or JSONL files
+				with timestamps, through the platform as if a device sent
+				them. Samples are sent at their original pace, accelerated or
+				at a fixed interval, and the recording can be looped.
+*/
+
+package sim
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+)
+
+// MinReplayPoll - shortest time between two polls of a replay
+const MinReplayPoll = 10 * time.Millisecond
+
+// Recording type - recorded readings of a device, oldest first, and the keys
+// found in them, in the order they were first seen
+type Recording struct {
+	Keys     []string
+	Readings []Reading
+}
+
+// Pacing type - how fast a recording is replayed. A non zero Interval sends one
+// reading per interval, whatever their times. Otherwise readings keep the time
+// between them divided by Speed (1, or 0, is the original pace).
+type Pacing struct {
+	Speed    float64
+	Interval time.Duration
+}
+
+// Replay type - collector sending the readings of a recording into SAMPLES table,
+// like the simulated device it replaces
+type Replay struct {
+	mutex     sync.Mutex
+	recording Recording
+	pacing    Pacing
+	loop      bool
+	clock     Clock
+	interval  time.Duration
+	started   time.Time
+	next      int
+}
+
+// NewReplay - Return a replay of recording with pacing, starting over at the end
+// when loop is true. A nil clock is the real one. The first reading is sent on
+// the first Collect.
+func NewReplay(recording Recording, pacing Pacing, loop bool, clock Clock) *Replay {
+	if pacing.Speed <= 0 {
+		pacing.Speed = 1
+	}
+	if clock == nil {
+		clock = RealClock{}
+	}
+
+	r := &Replay{recording: recording, pacing: pacing, loop: loop, clock: clock}
+	r.interval = r.pollInterval()
+
+	return r
+}
+
+// LoadRecording - Read a recording from a .csv or .jsonl file. Csv files have a
+// time column and one column per key, like the output of simulate. Jsonl files
+// have an object per line with a time field and the values as numbers, either
+// beside it or inside a values object. Times are RFC 3339 or Unix seconds.
+func LoadRecording(path string) (Recording, error) {
+	var recording Recording
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return recording, fmt.Errorf("[Sim] - Error reading recording: %v", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		recording, err = readCSV(file)
+	case ".jsonl":
+		recording, err = readJSONL(file)
+	default:
+		return recording, fmt.Errorf("[Sim] - Recording %s must be .csv or .jsonl", path)
+	}
+
+	if err != nil {
+		return recording, fmt.Errorf("[Sim] - Error decoding recording %s: %v", path, err)
+	}
+
+	if len(recording.Readings) == 0 {
+		return recording, fmt.Errorf("[Sim] - Recording %s has no readings", path)
+	}
+
+	// Readings are replayed in the order they were taken
+	sort.SliceStable(recording.Readings, func(i, j int) bool {
+		return recording.Readings[i].Time.Before(recording.Readings[j].Time)
+	})
+
+	return recording, nil
+}
+
+// readCSV - Read a recording with a time column followed by a column per key.
+// Empty cells are values that were not recorded.
+func readCSV(r io.Reader) (Recording, error) {
+	var recording Recording
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+
+	if err != nil {
+		return recording, err
+	}
+
+	if len(header) < 2 || strings.TrimSpace(header[0]) != "time" {
+		return recording, fmt.Errorf("first column must be time, followed by the recorded values")
+	}
+
+	for _, key := range header[1:] {
+		recording.Keys = append(recording.Keys, strings.TrimSpace(key))
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return recording, err
+		}
+
+		t, err := parseRecordedTime(record[0])
+
+		if err != nil {
+			return recording, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		reading := Reading{Time: t}
+
+		for i, cell := range record[1:] {
+			if strings.TrimSpace(cell) == "" {
+				continue
+			}
+
+			value, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+
+			if err != nil {
+				return recording, fmt.Errorf("line %d: invalid value %q of %s", line, cell, recording.Keys[i])
+			}
+
+			reading.Points = append(reading.Points, collector.Point{Key: recording.Keys[i], Value: value})
+		}
+
+		recording.Readings = append(recording.Readings, reading)
+	}
+
+	return recording, nil
+}
+
+// readJSONL - Read a recording with a json object per line. Fields that are not
+// numbers (e.g. a device name) are ignored, and so are empty lines.
+func readJSONL(r io.Reader) (Recording, error) {
+	var recording Recording
+
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		content := bytes.TrimSpace(scanner.Bytes())
+
+		if len(content) == 0 {
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+
+		if err := json.Unmarshal(content, &fields); err != nil {
+			return recording, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		var timeField interface{}
+
+		if err := json.Unmarshal(fields["time"], &timeField); err != nil {
+			return recording, fmt.Errorf("line %d: missing time", line)
+		}
+
+		t, err := parseRecordedTime(fmt.Sprint(timeField))
+
+		if err != nil {
+			return recording, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		// Values inside a values object, or beside time
+		values := fields
+
+		if nested, ok := fields["values"]; ok {
+			values = nil
+
+			if err := json.Unmarshal(nested, &values); err != nil {
+				return recording, fmt.Errorf("line %d: values must be an object", line)
+			}
+		}
+
+		// Same order on every line, as keys of json objects have none
+		var keys []string
+
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		reading := Reading{Time: t}
+
+		for _, key := range keys {
+			var value float64
+
+			if key == "time" || json.Unmarshal(values[key], &value) != nil {
+				continue
+			}
+
+			if !seen[key] {
+				seen[key] = true
+				recording.Keys = append(recording.Keys, key)
+			}
+
+			reading.Points = append(reading.Points, collector.Point{Key: key, Value: value})
+		}
+
+		recording.Readings = append(recording.Readings, reading)
+	}
+
+	return recording, scanner.Err()
+}
+
+// parseRecordedTime - Parse the time of a recorded reading, RFC 3339 or Unix
+// seconds with decimals
+func parseRecordedTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC 3339 or Unix seconds", value)
+}
+
+// Name - replayed samples are stored in SAMPLES table
+func (r *Replay) Name() string {
+	return "SAMPLES"
+}
+
+// Variables - one variable per recorded key, with its position as code (1, 2,
+// ...) as channels of a profile without codes
+func (r *Replay) Variables() []collector.Variable {
+	var variables []collector.Variable
+
+	for i, key := range r.recording.Keys {
+		variables = append(variables, collector.Variable{Code: fmt.Sprint(i + 1), Key: key, Name: key})
+	}
+
+	return variables
+}
+
+// Interval - time between polls, see pollInterval
+func (r *Replay) Interval() time.Duration {
+	return r.interval
+}
+
+// pollInterval - Return the time between polls, short enough to send every reading
+// on time: half of the shortest time between two readings, at the replay pace
+func (r *Replay) pollInterval() time.Duration {
+	if r.pacing.Interval > 0 {
+		return r.pacing.Interval
+	}
+
+	shortest := time.Duration(0)
+	readings := r.recording.Readings
+
+	for i := 1; i < len(readings); i++ {
+		gap := readings[i].Time.Sub(readings[i-1].Time)
+
+		if gap > 0 && (shortest == 0 || gap < shortest) {
+			shortest = gap
+		}
+	}
+
+	// A single reading, or all of them at the same time
+	if shortest == 0 {
+		return collector.DefaultInterval
+	}
+
+	poll := time.Duration(float64(shortest) / r.pacing.Speed / 2)
+
+	if poll < MinReplayPoll {
+		return MinReplayPoll
+	}
+
+	return poll
+}
+
+// Collect - Return the points of the latest recorded reading due, or no points
+// when none is due. Older readings due at the same time are left out, so callers
+// storing every reading use CollectDue.
+func (r *Replay) Collect(ctx context.Context) ([]collector.Point, error) {
+	readings, err := r.CollectDue(ctx)
+
+	if err != nil || len(readings) == 0 {
+		return nil, err
+	}
+
+	return readings[len(readings)-1].Points, nil
+}
+
+// CollectDue - Return every recorded reading due since the previous call, each
+// one at the time it was due, or none when the next one is not due yet or the
+// recording ended without loop. Polls that fall behind (e.g. readings closer
+// than MinReplayPoll) get several readings, which keep their recorded spacing.
+func (r *Replay) CollectDue(ctx context.Context) ([]collector.Reading, error) {
+	var readings []collector.Reading
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+
+	// First reading is sent right away
+	if r.started.IsZero() {
+		r.started = now
+	}
+
+	if r.next == len(r.recording.Readings) {
+		if !r.loop {
+			return nil, nil
+		}
+
+		// Start over right after the last reading
+		r.started = now
+		r.next = 0
+	}
+
+	for r.next < len(r.recording.Readings) && !now.Before(r.due(r.next)) {
+		reading := r.recording.Readings[r.next]
+
+		readings = append(readings, collector.Reading{Time: r.due(r.next), Points: append([]collector.Point(nil), reading.Points...)})
+		r.next++
+	}
+
+	return readings, nil
+}
+
+// due - Return when reading i of the recording is sent
+func (r *Replay) due(i int) time.Time {
+	if r.pacing.Interval > 0 {
+		return r.started.Add(time.Duration(i) * r.pacing.Interval)
+	}
+
+	offset := r.recording.Readings[i].Time.Sub(r.recording.Readings[0].Time)
+
+	return r.started.Add(time.Duration(float64(offset) / r.pacing.Speed))
+}
@@ -0,0 +1,297 @@
+/*
+	Date	:	18/10/2026
+	File	:	replay_test.go
+	Overview: 	Tests of the recordings read from csv and jsonl files and of
+				the pace readings are replayed at.
+*/
+
+package sim
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+)
+
+// recorded - Return the time of a reading recorded seconds after 2020-01-01
+func recorded(seconds int) time.Time {
+	return time.Date(2020, 1, 1, 0, 0, seconds, 0, time.UTC)
+}
+
+// sameRecording - Report whether two recordings have the same keys and readings,
+// whatever the location of their times
+func sameRecording(a Recording, b Recording) bool {
+	if !reflect.DeepEqual(a.Keys, b.Keys) || len(a.Readings) != len(b.Readings) {
+		return false
+	}
+
+	for i := range a.Readings {
+		if !a.Readings[i].Time.Equal(b.Readings[i].Time) || !reflect.DeepEqual(a.Readings[i].Points, b.Readings[i].Points) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Recording
+		wantErr bool
+	}{
+		{
+			name:    "values and empty cells",
+			content: "time, t ,h\n2020-01-01T00:00:00Z,1.5,60\n1577836810,,61\n",
+			want: Recording{
+				Keys: []string{"t", "h"},
+				Readings: []Reading{
+					{Time: recorded(0), Points: []collector.Point{{Key: "t", Value: 1.5}, {Key: "h", Value: 60}}},
+					{Time: recorded(10), Points: []collector.Point{{Key: "h", Value: 61}}},
+				},
+			},
+		},
+		{
+			name:    "no time column",
+			content: "t,h\n1,2\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			content: "time,t\nyesterday,1\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			content: "time,t\n2020-01-01T00:00:00Z,warm\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing cells",
+			content: "time,t,h\n2020-01-01T00:00:00Z,1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readCSV(strings.NewReader(tt.content))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readCSV error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !sameRecording(got, tt.want) {
+				t.Errorf("readCSV = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadJSONL(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Recording
+		wantErr bool
+	}{
+		{
+			name:    "values beside time",
+			content: `{"time":"2020-01-01T00:00:00Z","t":1.5,"device":"gh1","h":60}` + "\n\n" + `{"time":1577836810,"t":2}` + "\n",
+			want: Recording{
+				Keys: []string{"h", "t"},
+				Readings: []Reading{
+					{Time: recorded(0), Points: []collector.Point{{Key: "h", Value: 60}, {Key: "t", Value: 1.5}}},
+					{Time: recorded(10), Points: []collector.Point{{Key: "t", Value: 2}}},
+				},
+			},
+		},
+		{
+			name:    "values object",
+			content: `{"time":"2020-01-01T00:00:00Z","values":{"t":3}}`,
+			want: Recording{
+				Keys:     []string{"t"},
+				Readings: []Reading{{Time: recorded(0), Points: []collector.Point{{Key: "t", Value: 3}}}},
+			},
+		},
+		{
+			name:    "missing time",
+			content: `{"t":1}`,
+			wantErr: true,
+		},
+		{
+			name:    "values not an object",
+			content: `{"time":"2020-01-01T00:00:00Z","values":[1,2]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			content: `{"time":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readJSONL(strings.NewReader(tt.content))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readJSONL error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !sameRecording(got, tt.want) {
+				t.Errorf("readJSONL = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayCollectDue(t *testing.T) {
+	// One reading a second, with a single value: its number
+	var recording Recording
+
+	for i := 0; i < 5; i++ {
+		recording.Readings = append(recording.Readings, Reading{Time: recorded(i), Points: []collector.Point{{Key: "n", Value: float64(i)}}})
+	}
+	recording.Keys = []string{"n"}
+
+	// Readings sent by each poll, as value@time since the start of the replay
+	tests := []struct {
+		name     string
+		pacing   Pacing
+		loop     bool
+		polls    []time.Duration
+		want     [][]string
+		interval time.Duration
+	}{
+		{
+			name:     "original pace",
+			polls:    []time.Duration{0, 500 * time.Millisecond, time.Second, 2 * time.Second},
+			want:     [][]string{{"0@0s"}, nil, {"1@1s"}, {"2@2s"}},
+			interval: 500 * time.Millisecond,
+		},
+		{
+			name:     "behind sends every overdue reading",
+			polls:    []time.Duration{0, 3500 * time.Millisecond, 4 * time.Second},
+			want:     [][]string{{"0@0s"}, {"1@1s", "2@2s", "3@3s"}, {"4@4s"}},
+			interval: 500 * time.Millisecond,
+		},
+		{
+			name:     "faster",
+			pacing:   Pacing{Speed: 2},
+			polls:    []time.Duration{0, 500 * time.Millisecond, 2 * time.Second, 3 * time.Second},
+			want:     [][]string{{"0@0s"}, {"1@500ms"}, {"2@1s", "3@1.5s", "4@2s"}, nil},
+			interval: 250 * time.Millisecond,
+		},
+		{
+			name:     "fixed interval",
+			pacing:   Pacing{Interval: 10 * time.Second},
+			polls:    []time.Duration{0, 5 * time.Second, 10 * time.Second},
+			want:     [][]string{{"0@0s"}, nil, {"1@10s"}},
+			interval: 10 * time.Second,
+		},
+		{
+			name:     "loop",
+			loop:     true,
+			polls:    []time.Duration{0, 10 * time.Second, 11 * time.Second},
+			want:     [][]string{{"0@0s"}, {"1@1s", "2@2s", "3@3s", "4@4s"}, {"0@11s"}},
+			interval: 500 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := recorded(0)
+			clock := NewSimClock(start)
+			replay := NewReplay(recording, tt.pacing, tt.loop, clock)
+
+			if got := replay.Interval(); got != tt.interval {
+				t.Errorf("Interval = %v, want %v", got, tt.interval)
+			}
+
+			var got [][]string
+
+			for _, poll := range tt.polls {
+				clock.Advance(start.Add(poll).Sub(clock.Now()))
+
+				readings, err := replay.CollectDue(context.Background())
+
+				if err != nil {
+					t.Fatalf("CollectDue error = %v", err)
+				}
+
+				var sent []string
+
+				for _, reading := range readings {
+					sent = append(sent, fmt.Sprintf("%v@%v", reading.Points[0].Value, reading.Time.Sub(start)))
+				}
+
+				got = append(got, sent)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readings sent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayCollect(t *testing.T) {
+	recording := Recording{Keys: []string{"n"}}
+
+	for i := 0; i < 3; i++ {
+		recording.Readings = append(recording.Readings, Reading{Time: recorded(i), Points: []collector.Point{{Key: "n", Value: float64(i)}}})
+	}
+
+	// Collect sends the latest reading due, -1 standing for none
+	tests := []struct {
+		name  string
+		polls []time.Duration
+		want  []float64
+	}{
+		{"on time", []time.Duration{0, 500 * time.Millisecond, time.Second}, []float64{0, -1, 1}},
+		{"behind", []time.Duration{0, 2 * time.Second, 3 * time.Second}, []float64{0, 2, -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := recorded(0)
+			clock := NewSimClock(start)
+			replay := NewReplay(recording, Pacing{}, false, clock)
+
+			var got []float64
+
+			for _, poll := range tt.polls {
+				clock.Advance(start.Add(poll).Sub(clock.Now()))
+
+				points, err := replay.Collect(context.Background())
+
+				if err != nil {
+					t.Fatalf("Collect error = %v", err)
+				}
+
+				if len(points) == 0 {
+					got = append(got, -1)
+				} else {
+					got = append(got, points[0].Value)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readings sent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,31 +3,159 @@
 	Date	:	15/06/2020
 	File	:	sim.go
 	Overview: 	Sim provides a simple interface in order to simulate an
-				external device. the only function here, generate 4 random
-				samples between 1 and 10. In the end the sample data generated
-				is returned.
+				external device. The Device collector stores the channels
+				of a device profile into SAMPLES table, or into its own
+				sub-table when it belongs to a fleet.
 */
 
 package sim
 
 import (
+	"context"
+	"math"
 	"math/rand"
+	"sync"
 	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
 )
 
-// GenerateSamples - This function will generate random data in order
-// to simulate an external device. Lets say 4 int samples!
-func GenerateSamples() []int {
-	sampleData := make([]int, 4)
+// Device type - collector of the channels of a simulated device. It has its own
+// random source and clock, so a device created with the same seed and a
+// simulated clock always generates the same values. Devices of a fleet have an
+// ID, which scopes their table and variable codes, and their own interval.
+type Device struct {
+	mutex    sync.Mutex
+	id       string
+	interval time.Duration
+	profile  Profile
+	rng      *rand.Rand
+	clock    Clock
+	started  time.Time
+	values   []float64
+	faults   faultState
+}
+
+// NewDevice - Return a simulated device with the channels of profile, which
+// must be valid (see Profile.Validate). A zero seed takes the seed of the
+// profile, or the current time when it has none. A nil clock is the real one.
+func NewDevice(profile Profile, seed int64, clock Clock) *Device {
+	if seed == 0 {
+		seed = profile.Seed
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	if clock == nil {
+		clock = RealClock{}
+	}
+
+	d := &Device{profile: profile, rng: rand.New(rand.NewSource(seed)), clock: clock, started: clock.Now()}
+
+	// Random walks start in the middle of their range
+	for _, c := range profile.Channels {
+		d.values = append(d.values, c.start())
+	}
+
+	return d
+}
+
+// ID - Return the ID of the device inside its fleet, empty for the single device
+func (d *Device) ID() string {
+	return d.id
+}
+
+// Interval - time between entries of the device, zero for the default one
+func (d *Device) Interval() time.Duration {
+	return d.interval
+}
+
+// Name - samples are stored in SAMPLES table, or in the sub-table of the device
+// when it belongs to a fleet (e.g. SAMPLES/greenhouse)
+func (d *Device) Name() string {
+	if d.id == "" {
+		return "SAMPLES"
+	}
+
+	return database.SubTable("SAMPLES", d.id)
+}
+
+// Variables - one variable per channel of the profile. Channels of fleet devices
+// are scoped to the device, e.g. code 1@greenhouse and name greenhouse Sample 1.
+func (d *Device) Variables() []collector.Variable {
+	var variables []collector.Variable
+
+	for _, c := range d.profile.Channels {
+		v := collector.Variable{Code: c.Code, Key: c.Name, Name: c.Label, Unit: c.Unit}
+
+		if d.id != "" {
+			v.Code = collector.DeviceCode(c.Code, d.id)
+			v.Name = d.id + " " + c.Label
+		}
 
-	//Different source for random usage
-	rand.Seed(time.Now().UnixNano())
+		variables = append(variables, v)
+	}
+
+	return variables
+}
+
+// Collect - Generate the next value of every channel. Faults of the profile may
+// make the device stall, not answer (collector.ErrNoAnswer), be disconnected
+// (collector.ErrDisconnected), repeat its last values or send spikes and NaN.
+func (d *Device) Collect(ctx context.Context) ([]collector.Point, error) {
+	var points []collector.Point
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	elapsed := d.clock.Now().Sub(d.started)
+	faults := d.profile.Faults.triggered(d.rng, &d.faults, elapsed)
+
+	if duration, ok := faults[FaultDisconnect]; ok {
+		d.faults.disconnectedUntil = elapsed + duration
+	}
+
+	if elapsed < d.faults.disconnectedUntil {
+		return nil, collector.ErrDisconnected
+	}
+
+	// Answer late, or not at all if the caller gives up first
+	if duration, ok := faults[FaultStall]; ok {
+		if err := d.clock.Sleep(ctx, duration); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := faults[FaultDrop]; ok {
+		return nil, collector.ErrNoAnswer
+	}
+
+	// Freeze on the last values sent
+	if duration, ok := faults[FaultStuck]; ok {
+		d.faults.stuckUntil = elapsed + duration
+		d.faults.stuck = append([]float64(nil), d.values...)
+	}
+
+	for i, c := range d.profile.Channels {
+		if elapsed < d.faults.stuckUntil {
+			points = append(points, collector.Point{Key: c.Name, Value: d.faults.stuck[i]})
+			continue
+		}
+
+		d.values[i] = c.next(d.rng, elapsed, d.values[i])
+		points = append(points, collector.Point{Key: c.Name, Value: d.values[i]})
+	}
+
+	// Spikes and NaN hit one channel and are not kept as the last value
+	if _, ok := faults[FaultSpike]; ok {
+		i := d.rng.Intn(len(points))
+		points[i].Value = d.profile.Channels[i].spike(d.rng)
+	}
 
-	//Collect 4 random int to generate desired sample
-	for i := range sampleData {
-		sampleData[i] = rand.Intn(10)
+	if _, ok := faults[FaultNaN]; ok {
+		points[d.rng.Intn(len(points))].Value = math.NaN()
 	}
 
-	//Return sample generated previously with 4 ints
-	return sampleData
+	return points, nil
 }
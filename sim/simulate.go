@@ -0,0 +1,113 @@
+/*
+	Date	:	18/10/2026
+	File	:	simulate.go
+	Overview: 	Simulate generates the readings of a simulated device over a
+				period of time with a simulated clock. With the same profile,
+				seed and times the readings are always the same, so they can
+				be compared against golden outputs.
+*/
+
+package sim
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+)
+
+// Reading type - values of every channel of a device at a given instant
+type Reading = collector.Reading
+
+// Simulate - Return the readings of a device with profile and seed taken every
+// interval, from start until start plus duration (not included). Readings lost
+// to faults are missing, and stalled readings keep the time they were answered.
+// As when collecting, a reading not answered within interval is given up.
+func Simulate(profile Profile, seed int64, start time.Time, duration time.Duration, interval time.Duration) ([]Reading, error) {
+	var readings []Reading
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("[Sim] - Interval must be positive")
+	}
+
+	clock := NewSimClock(start)
+	device := NewDevice(profile, seed, clock)
+
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += interval {
+		tick := start.Add(elapsed)
+		clock.Advance(tick.Sub(clock.Now()))
+
+		// Stalls past the next tick are given up, see toolset.StoreCollector
+		clock.SetDeadline(tick.Add(interval))
+		points, err := device.Collect(context.Background())
+
+		// Readings the device did not answer in time are left out
+		if err == collector.ErrNoAnswer || err == collector.ErrDisconnected || err == context.DeadlineExceeded {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		readings = append(readings, Reading{Time: clock.Now(), Points: points})
+	}
+
+	return readings, nil
+}
+
+// WriteCSV - Write readings as csv, one column per channel of profile
+func WriteCSV(w io.Writer, profile Profile, readings []Reading) error {
+	var header []string
+
+	for _, c := range profile.Channels {
+		header = append(header, c.Name)
+	}
+
+	if _, err := fmt.Fprintf(w, "time,%s\n", strings.Join(header, ",")); err != nil {
+		return err
+	}
+
+	for _, r := range readings {
+		line := []string{r.Time.UTC().Format(time.RFC3339Nano)}
+
+		for _, p := range r.Points {
+			line = append(line, strconv.FormatFloat(p.Value, 'g', -1, 64))
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\n", strings.Join(line, ",")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CompareGolden - Return an error naming the first line where output and golden
+// differ, or nil when they are equal
+func CompareGolden(output []byte, golden []byte) error {
+	outputLines := bytes.Split(output, []byte("\n"))
+	goldenLines := bytes.Split(golden, []byte("\n"))
+
+	for i := 0; i < len(outputLines) || i < len(goldenLines); i++ {
+		var got, want []byte
+
+		if i < len(outputLines) {
+			got = outputLines[i]
+		}
+		if i < len(goldenLines) {
+			want = goldenLines[i]
+		}
+
+		if !bytes.Equal(got, want) {
+			return fmt.Errorf("[Sim] - Output differs from golden at line %d: got %q, want %q", i+1, got, want)
+		}
+	}
+
+	return nil
+}
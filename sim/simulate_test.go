@@ -0,0 +1,175 @@
+/*
+	Date	:	18/10/2026
+	File	:	simulate_test.go
+	Overview: 	Tests of the readings generated over a simulated period,
+				compared with a golden file, of seeded devices and of
+				stalled readings given up after the interval.
+*/
+
+package sim
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// update - rewrite golden files with the current output instead of comparing
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestSimulateGolden(t *testing.T) {
+	profile, err := LoadProfile(filepath.Join("testdata", "weather.yaml"))
+
+	if err != nil {
+		t.Fatalf("LoadProfile error = %v", err)
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	readings, err := Simulate(profile, 42, start, 24*time.Hour, 10*time.Minute)
+
+	if err != nil {
+		t.Fatalf("Simulate error = %v", err)
+	}
+
+	if len(readings) != 144 {
+		t.Errorf("got %d readings, want 144", len(readings))
+	}
+
+	var output bytes.Buffer
+
+	if err := WriteCSV(&output, profile, readings); err != nil {
+		t.Fatalf("WriteCSV error = %v", err)
+	}
+
+	golden := filepath.Join("testdata", "weather.golden.csv")
+
+	if *update {
+		if err := ioutil.WriteFile(golden, output.Bytes(), 0644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(golden)
+
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+
+	if err := CompareGolden(output.Bytes(), want); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeviceSeed(t *testing.T) {
+	profile, err := LoadProfile(filepath.Join("testdata", "weather.yaml"))
+
+	if err != nil {
+		t.Fatalf("LoadProfile error = %v", err)
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Readings of a device with seed, one every 10 minutes of a simulated clock
+	collect := func(seed int64) [][]float64 {
+		clock := NewSimClock(start)
+		device := NewDevice(profile, seed, clock)
+
+		var readings [][]float64
+
+		for i := 0; i < 20; i++ {
+			points, err := device.Collect(context.Background())
+
+			if err != nil {
+				t.Fatalf("Collect error = %v", err)
+			}
+
+			var values []float64
+
+			for _, p := range points {
+				values = append(values, p.Value)
+			}
+			readings = append(readings, values)
+
+			clock.Advance(10 * time.Minute)
+		}
+
+		return readings
+	}
+
+	equal := func(a [][]float64, b [][]float64) bool {
+		for i := range a {
+			if len(a[i]) != len(b[i]) {
+				return false
+			}
+			for j := range a[i] {
+				if a[i][j] != b[i][j] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	tests := []struct {
+		name      string
+		seed      int64
+		other     int64
+		wantEqual bool
+	}{
+		{"same seed", 42, 42, true},
+		{"other seed", 42, 7, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := equal(collect(tt.seed), collect(tt.other)); got != tt.wantEqual {
+				t.Errorf("readings of seeds %d and %d equal = %v, want %v", tt.seed, tt.other, got, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestSimulateStall(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		stallFor string
+		want     int
+		late     time.Duration
+	}{
+		{"answered late", "4m", 6, 4 * time.Minute},
+		{"given up at the next tick", "15m", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := DefaultProfile()
+			profile.Faults = Faults{Stall: 1, StallFor: tt.stallFor}
+
+			if err := profile.Validate(); err != nil {
+				t.Fatalf("Validate error = %v", err)
+			}
+
+			readings, err := Simulate(profile, 1, start, time.Hour, 10*time.Minute)
+
+			if err != nil {
+				t.Fatalf("Simulate error = %v", err)
+			}
+
+			if len(readings) != tt.want {
+				t.Fatalf("got %d readings, want %d", len(readings), tt.want)
+			}
+
+			for i, r := range readings {
+				if want := start.Add(time.Duration(i)*10*time.Minute + tt.late); !r.Time.Equal(want) {
+					t.Errorf("reading %d at %v, want %v", i, r.Time, want)
+				}
+			}
+		})
+	}
+}
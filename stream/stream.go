@@ -0,0 +1,127 @@
+/*
+	Date	:	18/10/2026
+	File	:	stream.go
+	Overview: 	Stream provides a publish/subscribe hub for new entries. The
+				collectors publish every entry written to the database and
+				each subscriber receives the variables it asked for through
+				a bounded buffer, so a slow subscriber never stalls them.
+*/
+
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultBuffer - number of events kept for a subscriber that is not reading
+const DefaultBuffer = 64
+
+// Event type - values of the variables of one entry written to a table, at the
+// instant of its key, so events match the entries read back from the table
+type Event struct {
+	Table  string             `json:"table"`
+	Time   time.Time          `json:"time"`
+	Values map[string]float64 `json:"values"`
+}
+
+// Subscription type - events published since Subscribe, filtered by variable
+type Subscription struct {
+	Events <-chan Event
+
+	events  chan Event
+	vars    map[string]bool
+	dropped int
+}
+
+// Hub type - set of subscriptions receiving every event published
+type Hub struct {
+	mutex         sync.Mutex
+	subscriptions map[*Subscription]bool
+}
+
+// DefaultHub - hub where the collectors publish new entries
+var DefaultHub = NewHub()
+
+// NewHub - Return a hub without subscriptions
+func NewHub() *Hub {
+	return &Hub{subscriptions: make(map[*Subscription]bool)}
+}
+
+// Subscribe - Return a subscription to events with any of the variables vars
+// (every variable when vars is empty), keeping up to buffer events
+func (h *Hub) Subscribe(vars []string, buffer int) *Subscription {
+	events := make(chan Event, buffer)
+	s := &Subscription{Events: events, events: events, vars: make(map[string]bool)}
+
+	for _, v := range vars {
+		s.vars[v] = true
+	}
+
+	h.mutex.Lock()
+	h.subscriptions[s] = true
+	h.mutex.Unlock()
+
+	return s
+}
+
+// Unsubscribe - Stop sending events to s and close its channel
+func (h *Hub) Unsubscribe(s *Subscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.subscriptions[s] {
+		delete(h.subscriptions, s)
+		close(s.events)
+	}
+}
+
+// Publish - Send event to every subscription interested in any of its variables.
+// It never blocks: when a subscription buffer is full the event is dropped for it.
+func (h *Hub) Publish(event Event) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for s := range h.subscriptions {
+		filtered := s.filter(event)
+
+		if filtered == nil {
+			continue
+		}
+
+		select {
+		case s.events <- *filtered:
+		default:
+			s.dropped++
+		}
+	}
+}
+
+// Dropped - Return the number of events dropped because s was not reading
+func (h *Hub) Dropped(s *Subscription) int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return s.dropped
+}
+
+// filter - Return event with only the variables of s, or nil if none is left
+func (s *Subscription) filter(event Event) *Event {
+	if len(s.vars) == 0 {
+		return &event
+	}
+
+	filtered := Event{Table: event.Table, Time: event.Time, Values: make(map[string]float64)}
+
+	for code, value := range event.Values {
+		if s.vars[code] {
+			filtered.Values[code] = value
+		}
+	}
+
+	if len(filtered.Values) == 0 {
+		return nil
+	}
+
+	return &filtered
+}
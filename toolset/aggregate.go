@@ -0,0 +1,172 @@
+/*
+	Date	:	18/10/2026
+	File	:	aggregate.go
+	Overview: 	Aggregate computes summary statistics (min, max, sum, count,
+				mean, stddev, median and percentiles) over the values stored
+				for one or more variables, and prints them in a table.
+*/
+
+package toolset
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+// Aggregate type - summary statistics of one variable
+type Aggregate struct {
+	Variable string  `json:"variable"`
+	Count    int     `json:"count"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Sum      float64 `json:"sum"`
+	Mean     float64 `json:"mean"`
+	StdDev   float64 `json:"stddev"`
+	Median   float64 `json:"median"`
+	P95      float64 `json:"p95"`
+	P99      float64 `json:"p99"`
+}
+
+// AggregateFunctions - names of every statistic available through Aggregate.Get
+var AggregateFunctions = []string{"count", "min", "max", "sum", "mean", "stddev", "median", "p95", "p99"}
+
+// Get - returns the statistic with the given name (see AggregateFunctions)
+func (a Aggregate) Get(fn string) (float64, error) {
+	switch strings.ToLower(fn) {
+	case "count":
+		return float64(a.Count), nil
+	case "min":
+		return a.Min, nil
+	case "max":
+		return a.Max, nil
+	case "sum":
+		return a.Sum, nil
+	case "mean", "avg":
+		return a.Mean, nil
+	case "stddev":
+		return a.StdDev, nil
+	case "median":
+		return a.Median, nil
+	case "p95":
+		return a.P95, nil
+	case "p99":
+		return a.P99, nil
+	}
+
+	return 0, fmt.Errorf("[Toolset] - Unknown aggregate function %q", fn)
+}
+
+// ComputeAggregate takes the values of a variable and returns all its statistics.
+// With no values every statistic is left as 0.
+func ComputeAggregate(variable string, values []float64) Aggregate {
+	agg := Aggregate{Variable: variable, Count: len(values)}
+
+	if len(values) == 0 {
+		return agg
+	}
+
+	// Sorted copy, needed for min, max, median and percentiles
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	agg.Min = sorted[0]
+	agg.Max = sorted[len(sorted)-1]
+
+	for _, v := range sorted {
+		agg.Sum = agg.Sum + v
+	}
+	agg.Mean = ComputeAverage(sorted)
+
+	// Population standard deviation
+	squares := 0.0
+	for _, v := range sorted {
+		squares = squares + (v-agg.Mean)*(v-agg.Mean)
+	}
+	agg.StdDev = math.Sqrt(squares / float64(agg.Count))
+
+	agg.Median = percentile(sorted, 50)
+	agg.P95 = percentile(sorted, 95)
+	agg.P99 = percentile(sorted, 99)
+
+	return agg
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating
+// linearly between the two closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	rank := (p / 100) * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// GetAggregate take a database to lookup and a code string with variables wanted
+// by the user, and returns the statistics of all stored values of every variable
+func GetAggregate(db *bolt.DB, codeStr string) ([]Aggregate, error) {
+	var aggregates []Aggregate
+
+	codeArray := strings.Split(codeStr, ",")
+
+	err := db.View(func(tx *bolt.Tx) error {
+		// Unknown codes are ignored, as the other queries do
+		for _, code := range knownCodes(codeArray) {
+			series := readSeries(tx, code, nil, nil)
+			aggregates = append(aggregates, ComputeAggregate(code, series.Values()))
+		}
+		return nil
+	})
+
+	if err != nil {
+		WriteToLog(fmt.Sprintf("%s \t || [Database] \t Error while computing aggregates \n", GetFormatedTime()))
+	}
+
+	return aggregates, err
+}
+
+// PrintAggregate - Prints statistics of every variable in a well formated way,
+// one column per variable and one row per statistic
+func PrintAggregate(aggregates []Aggregate) {
+	var codes []string
+
+	for _, a := range aggregates {
+		codes = append(codes, a.Variable)
+	}
+
+	// Create a nice table header. In this case it is needed to add a 0 to code string
+	// in order to match the expected code received
+	FormatTableHeader(fmt.Sprintf("0,%s", strings.Join(codes, ",")), len(aggregates))
+
+	labels := map[string]string{
+		"count":  "Count",
+		"min":    "Min",
+		"max":    "Max",
+		"sum":    "Sum",
+		"mean":   "Mean",
+		"stddev": "StdDev",
+		"median": "Median",
+		"p95":    "P95",
+		"p99":    "P99",
+	}
+
+	for _, fn := range AggregateFunctions {
+		fmt.Printf("|%s\t", labels[fn])
+		for _, a := range aggregates {
+			value, _ := a.Get(fn)
+			printValueCell(value)
+		}
+		fmt.Printf("|\n")
+	}
+
+	// Display bottom of table in a nice way too
+	fmt.Printf("+-------+")
+
+	for i := 0; i < len(aggregates); i++ {
+		fmt.Printf("---------------+")
+	}
+	fmt.Printf("\n")
+}
@@ -0,0 +1,112 @@
+/*
+	Date	:	18/10/2026
+	File	:	aggregate_test.go
+	Overview: 	Tests of the statistics computed by the aggregation engine.
+*/
+
+package toolset
+
+import (
+	"math"
+	"testing"
+)
+
+// closeTo - Report whether two statistics are equal, but for rounding errors
+func closeTo(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{"single value", []float64{7}, 95, 7},
+		{"lowest", []float64{1, 2, 3, 4}, 0, 1},
+		{"highest", []float64{1, 2, 3, 4}, 100, 4},
+		{"exact rank", []float64{1, 2, 3, 4, 5}, 50, 3},
+		{"interpolated", []float64{1, 2, 3, 4}, 50, 2.5},
+		{"p95 of 11 values", []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 95, 9.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); !closeTo(got, tt.want) {
+				t.Errorf("percentile(%v, %v) = %v, want %v", tt.sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeAggregate(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   Aggregate
+	}{
+		{
+			name: "no values",
+			want: Aggregate{Variable: "c"},
+		},
+		{
+			name:   "unsorted values",
+			values: []float64{4, 2, 8, 6},
+			want:   Aggregate{Variable: "c", Count: 4, Min: 2, Max: 8, Sum: 20, Mean: 5, StdDev: math.Sqrt(5), Median: 5, P95: 7.7, P99: 7.94},
+		},
+		{
+			name:   "equal values",
+			values: []float64{3, 3, 3},
+			want:   Aggregate{Variable: "c", Count: 3, Min: 3, Max: 3, Sum: 9, Mean: 3, Median: 3, P95: 3, P99: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeAggregate("c", tt.values)
+
+			if got.Variable != tt.want.Variable || got.Count != tt.want.Count {
+				t.Fatalf("got %s with %d values, want %s with %d", got.Variable, got.Count, tt.want.Variable, tt.want.Count)
+			}
+
+			for _, fn := range AggregateFunctions {
+				value, _ := got.Get(fn)
+				want, _ := tt.want.Get(fn)
+
+				if !closeTo(value, want) {
+					t.Errorf("%s = %v, want %v", fn, value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateGet(t *testing.T) {
+	agg := Aggregate{Count: 2, Min: 1, Max: 3, Sum: 4, Mean: 2, StdDev: 1, Median: 2, P95: 2.9, P99: 2.98}
+
+	tests := []struct {
+		fn      string
+		want    float64
+		wantErr bool
+	}{
+		{fn: "count", want: 2},
+		{fn: "MIN", want: 1},
+		{fn: "avg", want: 2},
+		{fn: "p99", want: 2.98},
+		{fn: "p50", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			got, err := agg.Get(tt.fn)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get(%q) error = %v, want error %v", tt.fn, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Get(%q) = %v, want %v", tt.fn, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+	Date	:	18/10/2026
+	File	:	join.go
+	Overview: 	Join matches the points of several series by timestamp, so
+				values of OS and SAMPLES tables are only shown side by side
+				when they were collected at (nearly) the same instant. Series
+				without a point close enough are reported as gaps.
+*/
+
+package toolset
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JoinTolerance - maximum distance between two points to be joined into the
+// same row. The tickers of OS and SAMPLES tables run apart by less than that.
+var JoinTolerance = 500 * time.Millisecond
+
+// Row type - values of several series matched at one instant. Values follow the
+// order of the joined series and a nil value is a gap, meaning that series has
+// no point within the tolerance.
+type Row struct {
+	Time   time.Time  `json:"time"`
+	Values []*float64 `json:"values"`
+}
+
+// Join - matches the points of the given series by timestamp. Every row starts at
+// the earliest point not joined yet and takes, from each other series, its next
+// point if it is within tolerance of the row time. A point closer to the next
+// point of the series starting the row is left for the row of that point, so
+// every point is joined to its nearest row. Rows come oldest first.
+func Join(series []Series, tolerance time.Duration) []Row {
+	var rows []Row
+
+	// Next point to join of every series
+	next := make([]int, len(series))
+
+	for {
+		// Row starts at the earliest point still to join
+		first := -1
+		var rowTime time.Time
+
+		for i, s := range series {
+			if next[i] < len(s.Points) && (first < 0 || s.Points[next[i]].Time.Before(rowTime)) {
+				rowTime = s.Points[next[i]].Time
+				first = i
+			}
+		}
+
+		// Every point was joined already
+		if first < 0 {
+			return rows
+		}
+
+		row := Row{Time: rowTime, Values: make([]*float64, len(series))}
+
+		// Next row of the series starting this one, if any
+		var nextTime *time.Time
+
+		if next[first]+1 < len(series[first].Points) {
+			nextTime = &series[first].Points[next[first]+1].Time
+		}
+
+		for i, s := range series {
+			if next[i] >= len(s.Points) {
+				continue
+			}
+
+			t := s.Points[next[i]].Time
+			distance := absDuration(t.Sub(rowTime))
+
+			if distance > tolerance {
+				continue
+			}
+
+			// Closer to the next row, so it waits for it
+			if i != first && nextTime != nil && absDuration(nextTime.Sub(t)) < distance {
+				continue
+			}
+
+			value := s.Points[next[i]].Value
+			row.Values[i] = &value
+			next[i]++
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// absDuration - returns the distance between two instants, whatever their order
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
+
+// printRows - Prints joined rows in a well formated way, with the row time in
+// the first column and a - for every gap
+func printRows(rows []Row, series []Series) {
+	// Display a nice header for our table
+	printRowBorder(len(series))
+	fmt.Printf("| Time \t\t\t")
+	for _, s := range series {
+		fmt.Printf("| %s \t", variableLabel(s.Variable))
+	}
+	fmt.Printf("|\n")
+	printRowBorder(len(series))
+
+	// Start printing info in table
+	for _, row := range rows {
+		fmt.Printf("| %s \t", row.Time.Format(TimeFormat))
+		for _, value := range row.Values {
+			if value == nil {
+				fmt.Printf("| - \t\t")
+			} else {
+				printValueCell(*value)
+			}
+		}
+		fmt.Printf("|\n")
+	}
+
+	// Display bottom of table in a nice way too
+	printRowBorder(len(series))
+}
+
+// printRowBorder - Prints a border line for a table with time and n variables
+func printRowBorder(n int) {
+	fmt.Printf("+-----------------------+%s\n", strings.Repeat("---------------+", n))
+}
@@ -0,0 +1,100 @@
+/*
+	Date	:	18/10/2026
+	File	:	join_test.go
+	Overview: 	Tests of the matching of several series by timestamp.
+*/
+
+package toolset
+
+import (
+	"testing"
+	"time"
+)
+
+// series - Return a series with a point of value 1, 2, ... at every offset, in
+// milliseconds since base
+func series(base time.Time, name string, offsets ...int) Series {
+	s := Series{Variable: name}
+
+	for i, ms := range offsets {
+		s.Points = append(s.Points, Point{Time: base.Add(time.Duration(ms) * time.Millisecond), Value: float64(i + 1)})
+	}
+
+	return s
+}
+
+func TestJoin(t *testing.T) {
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	// Every row is its offset, in milliseconds, and its values, 0 for a gap
+	type row struct {
+		at     int
+		values []float64
+	}
+
+	tests := []struct {
+		name   string
+		series []Series
+		want   []row
+	}{
+		{
+			name:   "aligned points",
+			series: []Series{series(base, "samples", 0, 1000), series(base, "os", 0, 1000)},
+			want:   []row{{0, []float64{1, 1}}, {1000, []float64{2, 2}}},
+		},
+		{
+			name:   "point after the row within tolerance",
+			series: []Series{series(base, "samples", 0, 1000), series(base, "os", 200, 1300)},
+			want:   []row{{0, []float64{1, 1}}, {1000, []float64{2, 2}}},
+		},
+		{
+			name:   "point before the row within tolerance",
+			series: []Series{series(base, "samples", 300, 1300), series(base, "os", 0, 1000)},
+			want:   []row{{0, []float64{1, 1}}, {1000, []float64{2, 2}}},
+		},
+		{
+			name:   "point joined to the nearest row",
+			series: []Series{series(base, "samples", 0, 500), series(base, "os", 490)},
+			want:   []row{{0, []float64{1, 0}}, {490, []float64{2, 1}}},
+		},
+		{
+			name:   "point beyond tolerance is a row of its own",
+			series: []Series{series(base, "samples", 0), series(base, "os", 600)},
+			want:   []row{{0, []float64{1, 0}}, {600, []float64{0, 1}}},
+		},
+		{
+			name:   "series without points",
+			series: []Series{series(base, "samples", 0, 1000), series(base, "os")},
+			want:   []row{{0, []float64{1, 0}}, {1000, []float64{2, 0}}},
+		},
+		{
+			name:   "no series",
+			series: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := Join(tt.series, 500*time.Millisecond)
+
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d", len(rows), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				if at := base.Add(time.Duration(want.at) * time.Millisecond); !rows[i].Time.Equal(at) {
+					t.Errorf("row %d time = %v, want %v", i, rows[i].Time, at)
+				}
+
+				for j, value := range rows[i].Values {
+					switch {
+					case value == nil && want.values[j] != 0:
+						t.Errorf("row %d has a gap of %s, want %v", i, tt.series[j].Variable, want.values[j])
+					case value != nil && *value != want.values[j]:
+						t.Errorf("row %d %s = %v, want %v", i, tt.series[j].Variable, *value, want.values[j])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+/*
+	Date	:	18/10/2026
+	File	:	output.go
+	Overview: 	Output prints query results in the format chosen by the user:
+				the usual tables, JSON or CSV, so results can be read by other
+				programs and scripts.
+*/
+
+package toolset
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats - output formats accepted by PrintSeries and PrintAverages
+var Formats = []string{"table", "json", "csv"}
+
+// PrintSeries - Prints series as a table (see PrintLastN), as JSON or as CSV. CSV
+// has one row per joined instant (see Join) and empty cells for gaps.
+func PrintSeries(series []Series, format string) error {
+	switch strings.ToLower(format) {
+	case "table":
+		PrintLastN(series)
+		return nil
+
+	case "json":
+		return printJSON(series)
+
+	case "csv":
+		writer := csv.NewWriter(os.Stdout)
+
+		header := []string{"time"}
+		for _, s := range series {
+			header = append(header, s.Variable)
+		}
+		writer.Write(header)
+
+		for _, row := range Join(series, JoinTolerance) {
+			record := []string{row.Time.Format(time.RFC3339Nano)}
+
+			for _, value := range row.Values {
+				if value == nil {
+					record = append(record, "")
+				} else {
+					record = append(record, strconv.FormatFloat(*value, 'f', -1, 64))
+				}
+			}
+			writer.Write(record)
+		}
+
+		writer.Flush()
+		return writer.Error()
+	}
+
+	return fmt.Errorf("[Toolset] - Unknown format %q, use %s", format, strings.Join(Formats, ", "))
+}
+
+// PrintAverages - Prints the mean of every aggregate as a table (see PrintAverage),
+// as JSON or as CSV
+func PrintAverages(aggregates []Aggregate, format string) error {
+	switch strings.ToLower(format) {
+	case "table":
+		// Create a nice table header. In this case it is needed to add a 0 to code string
+		// in order to match the expected code received
+		var codes []string
+		for _, a := range aggregates {
+			codes = append(codes, a.Variable)
+		}
+		FormatTableHeader(fmt.Sprintf("0,%s", strings.Join(codes, ",")), len(aggregates))
+
+		fmt.Printf("|Avg.\t")
+		for i := 0; i < len(aggregates); i++ {
+			printValueCell(aggregates[i].Mean)
+		}
+		fmt.Printf("|\n")
+
+		// Display bottom of table in a nice way too
+		fmt.Printf("+-------+")
+
+		for i := 0; i < len(aggregates); i++ {
+			fmt.Printf("---------------+")
+		}
+		fmt.Printf("\n")
+		return nil
+
+	case "json":
+		averages := make(map[string]float64)
+
+		for _, a := range aggregates {
+			averages[a.Variable] = a.Mean
+		}
+		return printJSON(averages)
+
+	case "csv":
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write([]string{"variable", "mean"})
+
+		for _, a := range aggregates {
+			writer.Write([]string{a.Variable, strconv.FormatFloat(a.Mean, 'f', -1, 64)})
+		}
+
+		writer.Flush()
+		return writer.Error()
+	}
+
+	return fmt.Errorf("[Toolset] - Unknown format %q, use %s", format, strings.Join(Formats, ", "))
+}
+
+// printJSON - Prints data as indented JSON
+func printJSON(data interface{}) error {
+	dataBytes, err := json.MarshalIndent(data, "", "  ")
+
+	if err != nil {
+		return fmt.Errorf("[Toolset] - Error encoding JSON: %v", err)
+	}
+
+	fmt.Printf("%s\n", dataBytes)
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+	Date	:	18/10/2026
+	File	:	series.go
+	Overview: 	Series provides the typed result returned by every query: one
+				series per variable, with the timestamp and float value of each
+				stored point. It also knows where each variable is stored, from
+				the registered collectors, and how to read its points with a
+				cursor.
+*/
+
+package toolset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
+
+	"github.com/boltdb/bolt"
+)
+
+// Point type - one stored value of a variable at a given instant
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Series type - points of one variable, oldest first
+type Series struct {
+	Variable string  `json:"variable"`
+	Points   []Point `json:"points"`
+}
+
+// Values - returns only the values of the series points
+func (s Series) Values() []float64 {
+	values := make([]float64, len(s.Points))
+
+	for i, p := range s.Points {
+		values[i] = p.Value
+	}
+
+	return values
+}
+
+// readSeries - reads every raw point of variable code with key between fromKey
+// and toKey (both included). A nil fromKey starts at the first entry and a nil
+// toKey goes until the last one.
+func readSeries(tx *bolt.Tx, code string, fromKey []byte, toKey []byte) Series {
+	return toSeries(code, readRollups(tx, variableBucket(code), code, fromKey, toKey))
+}
+
+// readLastSeries - reads the last n points of variable code. The returned series
+// is shorter than n when the table does not have enough entries.
+func readLastSeries(tx *bolt.Tx, code string, n int) Series {
+	series := Series{Variable: code}
+
+	bucket := database.Table(tx, variableBucket(code))
+
+	// Tables that dont exist have no points
+	if bucket == nil {
+		return series
+	}
+
+	cursor := bucket.Cursor()
+
+	// Walk backwards from last entry, so points come newest first
+	for k, v := cursor.Last(); len(series.Points) < n && k != nil; k, v = cursor.Prev() {
+		if r, ok := decodeVariable(code, v); ok {
+			series.Points = append(series.Points, Point{Time: database.KeyTime(k), Value: r.Avg})
+		}
+	}
+
+	// Reverse to keep oldest first
+	for i, j := 0, len(series.Points)-1; i < j; i, j = i+1, j-1 {
+		series.Points[i], series.Points[j] = series.Points[j], series.Points[i]
+	}
+
+	return series
+}
+
+// knownCodes - returns the variable codes of a code array that can be queried,
+// in the order given
+func knownCodes(codeArray []string) []string {
+	var codes []string
+
+	for _, code := range codeArray {
+		if variableBucket(code) != "" {
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
+
+// variableBucket returns the table where a variable code is stored, or an
+// empty string for unknown codes
+func variableBucket(code string) string {
+	if c, _, ok := collector.Lookup(code); ok {
+		return c.Name()
+	}
+
+	return ""
+}
+
+// variableKey returns the json key used to store a variable code
+func variableKey(code string) string {
+	_, v, _ := collector.Lookup(code)
+
+	return v.Key
+}
+
+// variableLabel returns the name of a variable code shown in table headers
+func variableLabel(code string) string {
+	_, v, _ := collector.Lookup(code)
+
+	return v.Label()
+}
+
+// decodeVariable decodes a json value of a raw or rollup table and returns the
+// rollup of variable code, keeping its stored precision. A raw value is a rollup
+// of a single value. Returns false if the variable is not in the value.
+func decodeVariable(code string, value []byte) (database.Rollup, bool) {
+	rollups, err := database.DecodeRollups(value)
+
+	if err != nil {
+		return database.Rollup{}, false
+	}
+
+	r, ok := rollups[variableKey(code)]
+	return r, ok
+}
+
+// formatValue - renders a value for tables without losing its precision. Integer
+// values are shown without decimals and others with up to 4 decimals.
+func formatValue(value float64) string {
+	str := strconv.FormatFloat(value, 'f', 4, 64)
+
+	// Remove zeros that add no precision
+	if strings.Contains(str, ".") {
+		str = strings.TrimRight(strings.TrimRight(str, "0"), ".")
+	}
+
+	return str
+}
+
+// printValueCell - prints one table cell with a value, keeping columns aligned
+func printValueCell(value float64) {
+	str := formatValue(value)
+
+	// Fix tab space for values that fill the first tab stop
+	if len(str) >= 5 {
+		fmt.Printf("| %s \t", str)
+	} else {
+		fmt.Printf("| %s \t\t", str)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+	Date	:	18/10/2026
+	File	:	tier.go
+	Overview: 	Tier reads the points of a variable across its raw table and
+				its rollup tables (1 minute, 1 hour). Range queries prefer the
+				finest tier, windowed queries the coarsest one that keeps the
+				resolution asked, and other tiers only fill what is missing.
+*/
+
+package toolset
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
+
+	"github.com/boltdb/bolt"
+)
+
+// rollupPoint type - summary of the values of a variable inside a window
+// starting at Time. Raw points are rollups of a single value.
+type rollupPoint struct {
+	Time   time.Time
+	Rollup database.Rollup
+}
+
+// tierTable type - table holding a variable and the width of its windows,
+// zero for the raw table
+type tierTable struct {
+	name  string
+	width time.Duration
+}
+
+// variableTiers - returns the tables holding variable code, finest first
+func variableTiers(code string) []tierTable {
+	raw := variableBucket(code)
+	tiers := []tierTable{{name: raw}}
+
+	for _, tier := range database.RollupTiers {
+		tiers = append(tiers, tierTable{name: database.RollupTable(raw, tier), width: tier.Width})
+	}
+
+	return tiers
+}
+
+// readRollups - reads every entry of table with variable code and key between
+// fromKey and toKey (both included). Nil keys mean no limit.
+func readRollups(tx *bolt.Tx, table string, code string, fromKey []byte, toKey []byte) []rollupPoint {
+	var points []rollupPoint
+
+	bucket := database.Table(tx, table)
+
+	// Tables that dont exist have no points
+	if bucket == nil {
+		return nil
+	}
+
+	cursor := bucket.Cursor()
+
+	// Point to first entry of the range
+	k, v := cursor.First()
+	if fromKey != nil {
+		k, v = cursor.Seek(fromKey)
+	}
+
+	for ; k != nil; k, v = cursor.Next() {
+		if toKey != nil && bytes.Compare(k, toKey) > 0 {
+			break
+		}
+
+		// Nested buckets and values without the variable are skipped
+		if v == nil {
+			continue
+		}
+
+		if r, ok := decodeVariable(code, v); ok {
+			points = append(points, rollupPoint{Time: database.KeyTime(k), Rollup: r})
+		}
+	}
+
+	return points
+}
+
+// readFinest - reads the points of variable code between from and to from the
+// finest tier that has them. Coarser tiers only fill the time before the first
+// point already read, which is data expired from the finer tiers.
+func readFinest(tx *bolt.Tx, code string, from time.Time, to time.Time) []rollupPoint {
+	var points []rollupPoint
+
+	end := to
+
+	for _, tier := range variableTiers(code) {
+		// Only windows that end before the points already read
+		if len(points) > 0 {
+			end = points[0].Time.Add(-tier.width)
+		}
+
+		if end.Before(from) {
+			break
+		}
+
+		points = append(readRollups(tx, tier.name, code, database.TimeKey(from), database.TimeKey(end)), points...)
+
+		// Range is covered, no need to look at coarser tiers
+		if len(points) > 0 && !points[0].Time.After(from) {
+			break
+		}
+	}
+
+	return points
+}
+
+// readCoarsest - reads the points of variable code between from and to from the
+// coarsest tier whose windows fit a whole number of times into window. Finer
+// tiers only fill the time after its last window, not rolled up yet.
+func readCoarsest(tx *bolt.Tx, code string, from time.Time, to time.Time, window time.Duration) []rollupPoint {
+	var points []rollupPoint
+
+	tiers := variableTiers(code)
+	start := from
+
+	// Walk tiers from coarsest to finest
+	for i := len(tiers) - 1; i >= 0; i-- {
+		tier := tiers[i]
+
+		if tier.width > window || (tier.width > 0 && window%tier.width != 0) {
+			continue
+		}
+
+		part := readRollups(tx, tier.name, code, database.TimeKey(start), database.TimeKey(to))
+		points = append(points, part...)
+
+		// Next tier starts after the last window read
+		if len(part) > 0 {
+			start = part[len(part)-1].Time.Add(tier.width)
+		}
+	}
+
+	return points
+}
+
+// toSeries - converts rollup points into a series with the average of each one
+func toSeries(code string, points []rollupPoint) Series {
+	series := Series{Variable: code}
+
+	for _, p := range points {
+		series.Points = append(series.Points, Point{Time: p.Time, Value: p.Rollup.Avg})
+	}
+
+	return series
+}
@@ -0,0 +1,51 @@
+/*
+	Date	:	18/10/2026
+	File	:	tier_test.go
+	Overview: 	Tests of the tiers windowed queries read their points from.
+*/
+
+package toolset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestReadCoarsest(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	db := rangeDB(t, base)
+
+	tests := []struct {
+		name   string
+		from   time.Duration
+		to     time.Duration
+		window time.Duration
+		want   []float64
+	}{
+		{"hour tier", 0, 3 * time.Hour, time.Hour, []float64{1, 2, 99}},
+		{"multiple of an hour", 0, 3 * time.Hour, 2 * time.Hour, []float64{1, 2, 99}},
+		{"minute tier filled by raw points", 0, 3 * time.Hour, time.Minute, []float64{3, 4, 5, 6}},
+		{"not a multiple of a minute", 0, 3 * time.Hour, 90 * time.Second, []float64{5, 6}},
+		{"shorter than a minute", 0, 3 * time.Hour, 10 * time.Second, []float64{5, 6}},
+		{"both ends included", time.Hour, 2 * time.Hour, time.Hour, []float64{2, 99}},
+		{"empty range", 4 * time.Hour, 5 * time.Hour, time.Hour, []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []float64
+
+			db.View(func(tx *bolt.Tx) error {
+				got = toSeries("r1", readCoarsest(tx, "r1", base.Add(tt.from), base.Add(tt.to), tt.window)).Values()
+				return nil
+			})
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
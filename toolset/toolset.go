@@ -11,162 +11,231 @@ package toolset
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"math"
 	"os"
 	"runtime"
-	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
 	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
-	"github.com/itsMeDacarvalho/ubiwhere-challenge/sim"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/metrics"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/stream"
 
 	"github.com/boltdb/bolt"
 )
 
-// PrintAllSampleData show in terminal well formated SAMPLE table with all data
-func PrintAllSampleData(db *bolt.DB) error {
-	// Temporary struct to decode json
-	var tmpSample database.Sample
+// TimeFormat - layout used to show and read instants (yy/mm/dd hh:mm:ss)
+const TimeFormat = "06/01/02 15:04:05"
 
-	err := db.View(func(tx *bolt.Tx) error {
-		data := tx.Bucket([]byte("DB")).Bucket([]byte(strings.ToUpper("SAMPLES")))
-
-		//Print header
-		fmt.Printf("+--------------------------------------------------------+\n")
-		fmt.Printf("| Time \t\t\t S1 \t S2 \t S3 \t S4 \t | \n")
-		fmt.Printf("+--------------------------------------------------------+\n")
-		fmt.Printf("+--------------------------------------------------------+\n")
+// StoreCollector collects data from c and performs an entry on its table. Devices
+// may not answer, stall or send NaN: a collection is given up after the interval
+// of c, missed readings are counted (as failed writes too) and NaN or infinite
+// values are not stored. Every reading due of a collector.Backlog (e.g. a replay
+// polled late) gets its own entry, at the time of the reading.
+func StoreCollector(ctx context.Context, db *bolt.DB, c collector.Collector) error {
+	start := time.Now()
 
-		err := data.ForEach(func(k, v []byte) error {
-			// Decode json data values
-			json.Unmarshal([]byte(v), &tmpSample)
+	// A device that stalls past its interval misses this reading
+	ctx, cancel := context.WithTimeout(ctx, collector.Interval(c))
+	readings, err := collectReadings(ctx, c)
+	cancel()
 
-			fmt.Printf("| %s \t %d \t %d \t %d \t %d \t | \n", string(k), tmpSample.Sample1, tmpSample.Sample2, tmpSample.Sample3, tmpSample.Sample4)
-			fmt.Printf("+--------------------------------------------------------+\n")
+	recordConnection(c, err)
 
-			return nil
-		})
+	if err != nil {
+		recordMissed(c, err)
+		recordStore(c, start, time.Time{}, err, nil)
 		return err
-	})
+	}
 
-	return err
+	for _, reading := range readings {
+		// Values by json key, as they are stored
+		values := make(map[string]float64)
+		for _, p := range reading.Points {
+			values[p.Key] = p.Value
+		}
+
+		values, invalid := database.FiniteValues(values)
+
+		if invalid > 0 {
+			metrics.AddCounter(metrics.Name("invalid_values_total"), "Values not stored because they were NaN or infinite", metrics.Labels("table", c.Name()), float64(invalid))
+		}
+
+		// Nothing collected (e.g. an empty watch list), so nothing to store
+		if len(values) == 0 {
+			continue
+		}
+
+		var stored time.Time
+
+		if reading.Time.IsZero() {
+			stored, err = database.AddEntry(db, c.Name(), values)
+		} else {
+			stored, err = database.AddEntryAt(db, c.Name(), reading.Time, values)
+		}
+
+		recordStore(c, start, stored, err, values)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// PrintAllOSData show in terminal well formated OS table with all data
-func PrintAllOSData(db *bolt.DB) error {
-	// Temporary struct to decode json
-	var tmpOS database.PerformanceOS
+// collectReadings - Return the readings due of c: every one of a collector.Backlog,
+// or the points of Collect as a single reading without time, stored now
+func collectReadings(ctx context.Context, c collector.Collector) ([]collector.Reading, error) {
+	if b, ok := c.(collector.Backlog); ok {
+		return b.CollectDue(ctx)
+	}
+
+	points, err := c.Collect(ctx)
 
-	err := db.View(func(tx *bolt.Tx) error {
-		data := tx.Bucket([]byte("DB")).Bucket([]byte("OS"))
+	if err != nil {
+		return nil, err
+	}
 
-		//Print header
-		fmt.Printf("+------------------------------------------------------------------------+\n")
-		fmt.Printf("| Time \t\t\t CPU (%%) \t Used RAM / Total RAM (Mb)\t | \n")
-		fmt.Printf("+------------------------------------------------------------------------+\n")
-		fmt.Printf("+------------------------------------------------------------------------+\n")
+	return []collector.Reading{{Points: points}}, nil
+}
 
-		err := data.ForEach(func(k, v []byte) error {
-			// Decode json data values
-			json.Unmarshal([]byte(v), &tmpOS)
+// connections - devices seen disconnected, by table, and since when
+var (
+	connectionsMutex sync.Mutex
+	connections      = make(map[string]time.Time)
+)
 
-			fmt.Printf("| %s \t %.2f %% \t %d / %d Mb (%.2f %%)\t | \n", string(k), tmpOS.CPU, tmpOS.UsedRAM, tmpOS.TotalRAM, ((float64(tmpOS.UsedRAM) / float64(tmpOS.TotalRAM)) * 100))
-			fmt.Printf("+------------------------------------------------------------------------+\n")
+// recordConnection - log when the device of collector c disconnects and when it
+// answers again, and keep its connection state in metrics
+func recordConnection(c collector.Collector, err error) {
+	table := c.Name()
+	disconnected := err == collector.ErrDisconnected
 
-			return nil
-		})
-		return err
-	})
+	connectionsMutex.Lock()
+	since, wasDisconnected := connections[table]
 
-	return err
+	if disconnected && !wasDisconnected {
+		connections[table] = time.Now()
+	}
+	if !disconnected && wasDisconnected {
+		delete(connections, table)
+	}
+	connectionsMutex.Unlock()
+
+	switch {
+	case disconnected && !wasDisconnected:
+		WriteToLog(fmt.Sprintf("%s \t || [%s] \t Device disconnected \n", GetFormatedTime(), table))
+	case !disconnected && wasDisconnected:
+		WriteToLog(fmt.Sprintf("%s \t || [%s] \t Device reconnected after %s \n", GetFormatedTime(), table, time.Since(since).Round(time.Second)))
+	}
+
+	// Connected from the first reading answered, not only after a reconnection
+	switch {
+	case disconnected:
+		metrics.SetGauge(metrics.Name("connected"), "1 while the device of a table answers, 0 while it is disconnected", metrics.Labels("table", table), 0)
+	case err == nil || wasDisconnected:
+		metrics.SetGauge(metrics.Name("connected"), "1 while the device of a table answers, 0 while it is disconnected", metrics.Labels("table", table), 1)
+	}
 }
 
-// StoreDataOS get info from OS and perform an entry on database
-func StoreDataOS(db *bolt.DB) error {
-	infoRAM := collector.GetRAM()
-	err := database.AddSystemStat(db, collector.GetCPU(), infoRAM[0], infoRAM[1])
+// recordMissed - count a reading of collector c lost because of err, by reason
+func recordMissed(c collector.Collector, err error) {
+	reason := "error"
+
+	switch {
+	case err == collector.ErrNoAnswer:
+		reason = "no_answer"
+	case err == collector.ErrDisconnected:
+		reason = "disconnected"
+	case err == context.DeadlineExceeded:
+		reason = "timeout"
+	}
 
-	return err
+	metrics.AddCounter(metrics.Name("missed_readings_total"), "Readings lost because the device did not answer", metrics.Labels("table", c.Name(), "reason", reason), 1)
 }
 
-// StoreSample get samples from simulator and perform an entry on database
-func StoreSample(db *bolt.DB) error {
-	err := database.AddSample(db, sim.GenerateSamples())
+// recordStore - update metrics after an entry of collector c: latest collected
+// values, written entries or failures (collections that failed included) and the
+// time taken to collect and write.
+// Entries written with success are also published to stream subscribers, with
+// the instant they were stored under.
+func recordStore(c collector.Collector, start time.Time, stored time.Time, err error, values map[string]float64) {
+	table := c.Name()
 
-	return err
+	// One gauge for every variable, a key like rxBytes:eth0 being variable rxBytes
+	// with label eth0
+	for key, value := range values {
+		parts := append(strings.SplitN(key, collector.LabelSeparator, 2), "")
+
+		metrics.SetGauge(metrics.Name("value"), "Latest collected value of a variable", metrics.Labels("table", table, "variable", parts[0], "label", parts[1]), value)
+	}
+
+	if err != nil {
+		metrics.AddCounter(metrics.Name("write_failures_total"), "Entries that failed to be written", metrics.Labels("table", table), 1)
+	} else {
+		metrics.AddCounter(metrics.Name("writes_total"), "Entries written", metrics.Labels("table", table), 1)
+	}
+
+	metrics.Observe(metrics.Name("collection_duration_seconds"), "Time taken to collect and write an entry", metrics.Labels("table", table), time.Since(start).Seconds())
+
+	if err != nil {
+		return
+	}
+
+	// Publish values by variable code, as subscribers ask for them
+	event := stream.Event{Table: table, Time: stored, Values: make(map[string]float64)}
+
+	for _, v := range c.Variables() {
+		if value, ok := values[v.Key]; ok {
+			event.Values[v.Code] = value
+		}
+	}
+
+	stream.DefaultHub.Publish(event)
 }
 
+// Errors returned when the metrics asked for are not stored
+var (
+	ErrNotEnoughMetrics = errors.New("[Toolset] - Desired number of metrics exceed total size of table")
+	ErrNoMetrics        = errors.New("[Toolset] - There are no metrics in the range asked for")
+	ErrReversedRange    = errors.New("[Toolset] - Start of the range is after its end")
+)
+
 // GetLastN  take a database to lookup and a code string with variables and number
-// of metrics to return
-func GetLastN(db *bolt.DB, codeStr string) (map[int][]int, error) {
-	// Map with type of sample as key to array of last n values
-	sampleData := make(map[int][]int)
+// of metrics to return. It returns one series per known variable, oldest point first,
+// or ErrNotEnoughMetrics when a variable has less than n points
+func GetLastN(db *bolt.DB, codeStr string) ([]Series, error) {
+	var series []Series
 
 	// Code string to array - this code maps which variables user want to see
 	codeArray := strings.Split(codeStr, ",")
 
 	// Desired numberof metrics. This come from first element of codeStr
-	desiredN, _ := strconv.Atoi(codeArray[0])
+	desiredN, err := strconv.Atoi(strings.TrimSpace(codeArray[0]))
 
-	// Temporary structs to decode json
-	var tmpSamples database.Sample
-	var tmpOS database.PerformanceOS
+	if err != nil || desiredN <= 0 {
+		return nil, fmt.Errorf("[Toolset] - Number of metrics must be a positive number, got %q", codeArray[0])
+	}
 
 	dataException := false
 
 	// Start database transaction
-	err := db.View(func(tx *bolt.Tx) error {
-		// Get SAMPLES table data
-		sampleTable := tx.Bucket([]byte("DB")).Bucket([]byte("SAMPLES"))
-		osTable := tx.Bucket([]byte("DB")).Bucket([]byte("OS"))
-
-		//Create new cursor for SAMPLES and OS table and point to last entry
-		sampleCursor := sampleTable.Cursor()
-		osCursor := osTable.Cursor()
-
-		// Point to last entry in SAMPLES and OS tables
-		_, sampleValue := sampleCursor.Last()
-		_, osValue := osCursor.Last()
-
-		// Iterate n times over table using cursor and store data into sampleData map
-		for i := 0; i < desiredN; i++ {
-			if sampleValue == nil || osValue == nil {
-				fmt.Printf("[Info] - Desired number of metrics exceed total size of table.\n")
+	err = db.View(func(tx *bolt.Tx) error {
+		// Read last n points of every variable with its own cursor
+		for _, code := range knownCodes(codeArray[1:]) {
+			s := readLastSeries(tx, code, desiredN)
+
+			if len(s.Points) < desiredN {
 				dataException = true
 				break
 			}
-			// Decode value data that contains 4 ints for each sample
-			json.Unmarshal([]byte(sampleValue), &tmpSamples)
-			json.Unmarshal([]byte(osValue), &tmpOS)
-
-			// Create final map with desired info
-			if Find(codeArray, "c") {
-				sampleData[desiredN-i] = append(sampleData[desiredN-i], int(math.Round(tmpOS.CPU)))
-			}
-			if Find(codeArray, "r") {
-				sampleData[desiredN-i] = append(sampleData[desiredN-i], int(tmpOS.UsedRAM))
-			}
-			if Find(codeArray, "1") {
-				sampleData[desiredN-i] = append(sampleData[desiredN-i], tmpSamples.Sample1)
-			}
-			if Find(codeArray, "2") {
-				sampleData[desiredN-i] = append(sampleData[desiredN-i], tmpSamples.Sample2)
-			}
-			if Find(codeArray, "3") {
-				sampleData[desiredN-i] = append(sampleData[desiredN-i], tmpSamples.Sample3)
-			}
-			if Find(codeArray, "4") {
-				sampleData[desiredN-i] = append(sampleData[desiredN-i], tmpSamples.Sample4)
-			}
-
-			// Point cursor to the previous entry
-			_, sampleValue = sampleCursor.Prev()
-			_, osValue = osCursor.Prev()
+			series = append(series, s)
 		}
 
 		return nil
@@ -176,182 +245,165 @@ func GetLastN(db *bolt.DB, codeStr string) (map[int][]int, error) {
 		WriteToLog(fmt.Sprintf("%s \t || [Database] \t Error while getting metrics from SAMPLES table \n", GetFormatedTime()))
 	}
 
-	if dataException {
-		return nil, err
+	if err == nil && dataException {
+		return nil, ErrNotEnoughMetrics
 	}
-	return sampleData, err
+	return series, err
 }
 
-// PrintAverage gets a database to lookup and a code string with variables wanted by
-// the user, and prints the average for every variable
-func PrintAverage(db *bolt.DB, codeStr string) error {
-	var tmpOS database.PerformanceOS
-	var tmpSample database.Sample
-	var tmpData []int
-	var averageValues []float64
+// GetRange take a database to lookup, two instants and a code string with variables,
+// and returns one series per known variable with every point stored between from
+// and to (both included). Time already expired from raw tables is filled with the
+// averages of the finest rollup tier that still has it. Ranges without any point
+// return ErrNoMetrics, and ranges starting after their end ErrReversedRange.
+func GetRange(db *bolt.DB, from time.Time, to time.Time, codeStr string) ([]Series, error) {
+	var series []Series
+
+	if from.After(to) {
+		return nil, ErrReversedRange
+	}
 
+	// Code string to array - this code maps which variables user want to see
 	codeArray := strings.Split(codeStr, ",")
 
+	// Count points found, to warn about empty ranges
+	found := 0
+
+	// Start database transaction
 	err := db.View(func(tx *bolt.Tx) error {
-		for _, v := range codeArray {
-			tmpData = nil
-			if v == "c" {
-				data := tx.Bucket([]byte("DB")).Bucket([]byte("OS"))
-
-				data.ForEach(func(k, v []byte) error {
-					// Decode json
-					json.Unmarshal([]byte(v), &tmpOS)
-					tmpData = append(tmpData, int(math.Round(tmpOS.CPU)))
-					return nil
-				})
-
-				averageValues = append(averageValues, ComputeAverage(tmpData))
-			} else if v == "r" {
-				data := tx.Bucket([]byte("DB")).Bucket([]byte("OS"))
-
-				data.ForEach(func(k, v []byte) error {
-					// Decode json
-					json.Unmarshal([]byte(v), &tmpOS)
-					tmpData = append(tmpData, int(tmpOS.UsedRAM))
-					return nil
-				})
-
-				averageValues = append(averageValues, ComputeAverage(tmpData))
-			} else if v == "1" {
-				data := tx.Bucket([]byte("DB")).Bucket([]byte("SAMPLES"))
-
-				data.ForEach(func(k, v []byte) error {
-					// Decode json
-					json.Unmarshal([]byte(v), &tmpSample)
-					tmpData = append(tmpData, tmpSample.Sample1)
-					return nil
-				})
-
-				averageValues = append(averageValues, ComputeAverage(tmpData))
-			} else if v == "2" {
-				data := tx.Bucket([]byte("DB")).Bucket([]byte("SAMPLES"))
-
-				data.ForEach(func(k, v []byte) error {
-					// Decode json
-					json.Unmarshal([]byte(v), &tmpSample)
-					tmpData = append(tmpData, tmpSample.Sample2)
-					return nil
-				})
-
-				averageValues = append(averageValues, ComputeAverage(tmpData))
-			} else if v == "3" {
-				data := tx.Bucket([]byte("DB")).Bucket([]byte("SAMPLES"))
-
-				data.ForEach(func(k, v []byte) error {
-					// Decode json
-					json.Unmarshal([]byte(v), &tmpSample)
-					tmpData = append(tmpData, tmpSample.Sample3)
-					return nil
-				})
-
-				averageValues = append(averageValues, ComputeAverage(tmpData))
-			} else if v == "4" {
-				data := tx.Bucket([]byte("DB")).Bucket([]byte("SAMPLES"))
-
-				data.ForEach(func(k, v []byte) error {
-					// Decode json
-					json.Unmarshal([]byte(v), &tmpSample)
-					tmpData = append(tmpData, tmpSample.Sample4)
-					return nil
-				})
-
-				averageValues = append(averageValues, ComputeAverage(tmpData))
-			}
+		// Seek every variable table to the start of the range
+		for _, code := range knownCodes(codeArray) {
+			s := toSeries(code, readFinest(tx, code, from, to))
+			found = found + len(s.Points)
+			series = append(series, s)
 		}
+
 		return nil
 	})
 
-	// Create a nice table header. In this case it is needed to add a 0 to code string
-	// in order to match the expected code received
-	FormatTableHeader(fmt.Sprintf("0,%s", codeStr), len(averageValues))
+	if err != nil {
+		WriteToLog(fmt.Sprintf("%s \t || [Database] \t Error while getting metrics between %s and %s \n", GetFormatedTime(), from.Format(TimeFormat), to.Format(TimeFormat)))
+	}
+
+	if err == nil && found == 0 {
+		return nil, ErrNoMetrics
+	}
+	return series, err
+}
 
-	fmt.Printf("|Avg.\t")
-	for i := 0; i < len(averageValues); i++ {
-		// Fix tab space for values for values bigger than 1000
-		if averageValues[i] >= 1000 {
-			fmt.Printf("| %.2f \t", averageValues[i])
-		} else {
-			fmt.Printf("| %.2f \t\t", averageValues[i])
+// GetAll take a database to lookup and a code string with variables, and returns
+// one series per known variable with every raw point stored
+func GetAll(db *bolt.DB, codeStr string) ([]Series, error) {
+	var series []Series
+
+	err := db.View(func(tx *bolt.Tx) error {
+		for _, code := range knownCodes(strings.Split(codeStr, ",")) {
+			series = append(series, readSeries(tx, code, nil, nil))
 		}
+		return nil
+	})
 
+	if err != nil {
+		WriteToLog(fmt.Sprintf("%s \t || [Database] \t Error while getting all metrics \n", GetFormatedTime()))
 	}
-	fmt.Printf("|\n")
 
-	// Display bottom of table in a nice way too
-	fmt.Printf("+-------+")
+	return series, err
+}
 
-	for i := 0; i < len(averageValues); i++ {
-		fmt.Printf("---------------+")
+// DeviceCodes - Return codeStr with every code scoped to fleet device id, see
+// collector.DeviceCodes. Devices are the sub-tables of SAMPLES in db, so devices
+// of any session are found, and a device without one is unknown.
+func DeviceCodes(db *bolt.DB, codeStr string, id string) (string, error) {
+	tables, err := database.SubTables(db, "SAMPLES")
+
+	if err != nil {
+		return "", err
 	}
-	fmt.Printf("\n")
 
-	return err
+	if !Find(tables, database.SubTable("SAMPLES", id)) {
+		return "", fmt.Errorf("[Toolset] - Unknown device %s", id)
+	}
+
+	return collector.DeviceCodes(codeStr, id), nil
 }
 
-// ComputeAverage takes a array of ints and return the values average
-func ComputeAverage(values []int) float64 {
-	tmpSum := 0
-	var average float64
+// DecodeRange splits a range code string like "from,to,c,r,1" into its limits
+// and the code string with the wanted variables
+func DecodeRange(rangeStr string) (time.Time, time.Time, string, error) {
+	codeArray := strings.SplitN(rangeStr, ",", 3)
 
-	for _, v := range values {
-		tmpSum = tmpSum + v
+	if len(codeArray) < 3 {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("[Toolset] - Range needs a start, an end and at least one variable")
 	}
 
-	average = float64(tmpSum) / float64(len(values))
-	return average
-}
+	from, err := ParseTimeArg(codeArray[0])
 
-// PrintLastN - Prints all last n metrics in a well formated way
-func PrintLastN(data map[int][]int, codeStr string) {
-	iterationsLoop := len(data[1])
+	if err != nil {
+		return time.Time{}, time.Time{}, "", err
+	}
 
-	// Display a nice header for our table
-	FormatTableHeader(codeStr, iterationsLoop)
+	to, err := ParseTimeArg(codeArray[1])
 
-	// Sort keys to show correct info
-	var keys []int
-	for k := range data {
-		keys = append(keys, k)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", err
 	}
-	sort.Ints(keys)
 
-	// Start printing info in table
-	for i := 1; i <= len(data); i++ {
-		fmt.Printf("|%d\t", i)
-		for j := 0; j < iterationsLoop; j++ {
-			fmt.Printf("| %d \t\t", data[i][j])
+	return from, to, codeArray[2], nil
+}
+
+// ParseTimeArg - parse an instant given by the user. It accepts the full key
+// format (yy/mm/dd hh:mm:ss) or only hh:mm:ss / hh:mm, which are taken as today
+func ParseTimeArg(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	// Full date and time
+	if t, err := time.ParseInLocation(TimeFormat, value, time.Local); err == nil {
+		return t, nil
+	}
+
+	// Only time of day, so take today as date
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			now := time.Now()
+			return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local), nil
 		}
-		fmt.Printf("|\n")
 	}
 
-	// Display bottom of table in a nice way too
-	fmt.Printf("+-------+")
+	return time.Time{}, fmt.Errorf("[Toolset] - Invalid time %q, use yy/mm/dd hh:mm:ss or hh:mm:ss", value)
+}
 
-	for i := 0; i < iterationsLoop; i++ {
-		fmt.Printf("---------------+")
+// PrintAverage gets a database to lookup and a code string with variables wanted by
+// the user, and prints the average for every variable
+func PrintAverage(db *bolt.DB, codeStr string) error {
+	aggregates, err := GetAggregate(db, codeStr)
+
+	PrintAverages(aggregates, "table")
+
+	return err
+}
+
+// ComputeAverage takes a array of values and return the values average
+func ComputeAverage(values []float64) float64 {
+	tmpSum := 0.0
+	var average float64
+
+	for _, v := range values {
+		tmpSum = tmpSum + v
 	}
-	fmt.Printf("\n")
+
+	average = tmpSum / float64(len(values))
+	return average
+}
+
+// PrintLastN - Prints all points of the given series in a well formated way. Points
+// are matched by timestamp (see Join) and every row shows its time
+func PrintLastN(series []Series) {
+	printRows(Join(series, JoinTolerance), series)
 }
 
 // PrintMenu - Display well formated menu to user and return choosed option
 func PrintMenu() (string, string) {
 	reader := bufio.NewReader(os.Stdin)
-	optStr := ""
-
-	// Options map to encode user preferences
-	options := map[string]string{
-		"CPU":      "c",
-		"RAM":      "r",
-		"Sample 1": "1",
-		"Sample 2": "2",
-		"Sample 3": "3",
-		"Sample 4": "4",
-	}
 
 	// Display menu info
 	fmt.Printf("\n\n+---------------------------------------------------------------+\n")
@@ -360,6 +412,9 @@ func PrintMenu() (string, string) {
 	fmt.Printf("| 1 - Get last n metrics for all variables \t\t\t|\n")
 	fmt.Printf("| 2 - Get last n metrics for one or more variables \t\t|\n")
 	fmt.Printf("| 3 - Get an average of the value of one or more variables \t|\n")
+	fmt.Printf("| 4 - Get metrics between two instants for one or more variables|\n")
+	fmt.Printf("| 5 - Get statistics (min, max, p95...) of one or more variables|\n")
+	fmt.Printf("| 6 - Get one statistic per time window of one or more variables|\n")
 	fmt.Printf("| 0 - Exit \t\t\t\t\t\t\t|\n")
 	fmt.Printf("+---------------------------------------------------------------+\n")
 	fmt.Printf(">> Option: ")
@@ -382,20 +437,9 @@ func PrintMenu() (string, string) {
 		fmt.Printf("\n>> How many metrics: ")
 		n, _ := reader.ReadString('\n')
 		n = strings.TrimSuffix(n, suffix)
-		return opt, fmt.Sprintf("%s,c,r,1,2,3,4", n)
+		return opt, fmt.Sprintf("%s,%s", n, collector.BaseCodes())
 
 	case "2":
-		// Variable to store sorted keys
-		var tmpSort []string
-
-		// Iterate over keys to append in slice
-		for k := range options {
-			tmpSort = append(tmpSort, k)
-		}
-
-		// Sort slice
-		sort.Strings(tmpSort)
-
 		fmt.Printf("\n")
 
 		// Get number of metrics from user
@@ -403,78 +447,107 @@ func PrintMenu() (string, string) {
 		n, _ := reader.ReadString('\n')
 		n = strings.TrimSuffix(n, suffix)
 
-		optStr = fmt.Sprintf("%s%s,", optStr, n)
+		fmt.Printf("\n")
+
+		// Produce a code string like n,c,r,1, ... in order to know which variables user want
+		return opt, fmt.Sprintf("%s,%s", n, askVariables(reader, suffix))
 
+	case "3":
 		fmt.Printf("\n")
 
-		// Get user desired variables
-		for i := 0; i < len(tmpSort); i++ {
-			fmt.Printf(">> %s [y/n]: ", tmpSort[i])
+		// Produce a code string like c,r,1, ... in order to know which variables user want
+		return opt, askVariables(reader, suffix)
 
-			// Read user choice
-			n, _ := reader.ReadString('\n')
-			n = strings.TrimSuffix(n, suffix)
+	case "4":
+		fmt.Printf("\n")
 
-			// Append choice to optStr
-			if strings.ToLower(n) == "y" {
-				// Produce a code string like c,r,1, ... in order to know which variables user want
-				optStr = fmt.Sprintf("%s%s,", optStr, options[tmpSort[i]])
-			}
-		}
+		// Get range limits from user
+		fmt.Printf(">> From (yy/mm/dd hh:mm:ss or hh:mm:ss): ")
+		from, _ := reader.ReadString('\n')
+		from = strings.TrimSuffix(from, suffix)
 
-		// Remove last "," from code str and return opt and optStr
-		return opt, strings.TrimSuffix(optStr, ",")
+		fmt.Printf(">> To (yy/mm/dd hh:mm:ss or hh:mm:ss): ")
+		to, _ := reader.ReadString('\n')
+		to = strings.TrimSuffix(to, suffix)
 
-	case "3":
-		// Variable to store sorted keys
-		var tmpSort []string
+		fmt.Printf("\n")
 
-		// Iterate over keys to append in slice
-		for k := range options {
-			tmpSort = append(tmpSort, k)
-		}
+		// Produce a code string like from,to,c,r,1, ... to be decoded by DecodeRange
+		return opt, fmt.Sprintf("%s,%s,%s", from, to, askVariables(reader, suffix))
+
+	case "5":
+		fmt.Printf("\n")
 
-		// Sort slice to ask user in desired order
-		sort.Strings(tmpSort)
+		// Produce a code string like c,r,1, ... in order to know which variables user want
+		return opt, askVariables(reader, suffix)
 
+	case "6":
 		fmt.Printf("\n")
 
-		// Get user desired variables
-		for i := 0; i < len(tmpSort); i++ {
-			fmt.Printf(">> %s [y/n]: ", tmpSort[i])
+		// Get window query settings from user
+		fmt.Printf(">> Look back (e.g. 6h, 30m): ")
+		span, _ := reader.ReadString('\n')
+		span = strings.TrimSuffix(span, suffix)
 
-			// Read user choice
-			n, _ := reader.ReadString('\n')
-			n = strings.TrimSuffix(n, suffix)
+		fmt.Printf(">> Window size (e.g. 10s, 1m, 1h): ")
+		window, _ := reader.ReadString('\n')
+		window = strings.TrimSuffix(window, suffix)
 
-			// Append choice to optStr
-			if strings.ToLower(n) == "y" {
-				// Produce a code string like c,r,1, ... in order to know which variables user want
-				optStr = fmt.Sprintf("%s%s,", optStr, options[tmpSort[i]])
-			}
-		}
+		fmt.Printf(">> Function (%s): ", strings.Join(AggregateFunctions, ", "))
+		fn, _ := reader.ReadString('\n')
+		fn = strings.TrimSuffix(fn, suffix)
+
+		fmt.Printf(">> Format (table/json): ")
+		format, _ := reader.ReadString('\n')
+		format = strings.TrimSuffix(format, suffix)
 
-		// Remove last "," from code str and return opt and optStr
-		return opt, strings.TrimSuffix(optStr, ",")
+		fmt.Printf("\n")
+
+		// Produce a code string like span,window,fn,format,c,r,1, ... to be decoded by DecodeWindow
+		return opt, fmt.Sprintf("%s,%s,%s,%s,%s", span, window, fn, format, askVariables(reader, suffix))
 	}
 
 	// Return option
 	return opt, ""
 }
 
+// askVariables - Ask the user, one by one, which registered variables are wanted
+// and return a code string like c,r,1, ... Labeled variables are only offered when
+// the user asks for them.
+func askVariables(reader *bufio.Reader, suffix string) string {
+	optStr := ""
+
+	// Variables of disks, interfaces and processes only when the user wants them
+	fmt.Printf(">> Also choose disk, interface and process variables [y/n]: ")
+	labeled, _ := reader.ReadString('\n')
+	withLabeled := strings.ToLower(strings.TrimSuffix(labeled, suffix)) == "y"
+
+	// Get user desired variables, in the order collectors were registered
+	for _, v := range collector.Variables() {
+		if v.Labeled() && !withLabeled {
+			continue
+		}
+
+		fmt.Printf(">> %s [y/n]: ", v.Label())
+
+		// Read user choice
+		n, _ := reader.ReadString('\n')
+		n = strings.TrimSuffix(n, suffix)
+
+		// Append choice to optStr
+		if strings.ToLower(n) == "y" {
+			optStr = fmt.Sprintf("%s%s,", optStr, v.Code)
+		}
+	}
+
+	// Remove last "," from code str
+	return strings.TrimSuffix(optStr, ",")
+}
+
 // FormatTableHeader displays a custom table header for desired info
 // Header info string : first char must be the number of variables wanted by the user
-// and the other ones are... c-cpu; r-ram; 1-sample1; 2-sample2; 3-sample3; 4-sample4
+// and the other ones are codes of registered variables (see collector.Variables)
 func FormatTableHeader(headerInfo string, numberRows int) {
-	decodeStr := map[string]string{
-		"c": "CPU (%)",
-		"r": "RAM (Mb)",
-		"1": "Sample 1",
-		"2": "Sample 2",
-		"3": "Sample 3",
-		"4": "Sample 4",
-	}
-
 	// Convert header info string into an array in order to iterate it
 	codeArray := strings.Split(headerInfo, ",")
 
@@ -488,7 +561,7 @@ func FormatTableHeader(headerInfo string, numberRows int) {
 	// Get variable name from decode string map and produce the table header
 	fmt.Printf("|# \t")
 	for i := 1; i < len(codeArray); i++ {
-		fmt.Printf("| %s \t", decodeStr[codeArray[i]])
+		fmt.Printf("| %s \t", variableLabel(codeArray[i]))
 	}
 
 	fmt.Printf("|\n")
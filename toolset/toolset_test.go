@@ -0,0 +1,404 @@
+/*
+	Date	:	18/10/2026
+	File	:	toolset_test.go
+	Overview: 	Tests of the points read from raw and rollup tables, of the
+				codes of fleet devices found in a database and of the metrics
+				kept while collecting.
+*/
+
+package toolset
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/collector"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/metrics"
+
+	"github.com/boltdb/bolt"
+)
+
+// openTestDB - Return an empty database with root bucket, removed when the test ends
+func openTestDB(t *testing.T) *bolt.DB {
+	t.Helper()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte("DB"))
+		return err
+	})
+
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	return db
+}
+
+// putValue - Store the json value under instant at into table of db
+func putValue(t *testing.T, db *bolt.DB, table string, at time.Time, value string) {
+	t.Helper()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		return database.Table(tx, table).Put(database.TimeKey(at), []byte(value))
+	})
+
+	if err != nil {
+		t.Fatalf("put into %s: %v", table, err)
+	}
+}
+
+// rangeDB - Return a database with variable r1 of table TEST_RANGE stored in its
+// raw table and both rollup tables, registered until the test ends. Hours 0 and
+// 1 are only in TEST_RANGE_1H (hour 2 too, overlapping the finer tiers), minutes
+// 2:00 and 2:01 in TEST_RANGE_1M and the raw table keeps from 2:02 on.
+func rangeDB(t *testing.T, base time.Time) *bolt.DB {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	if err := database.CreateTable(db, "TEST_RANGE"); err != nil {
+		t.Fatalf("CreateTable error = %v", err)
+	}
+
+	collector.Register(collector.NewStored("TEST_RANGE", []collector.Variable{{Code: "r1", Key: "v"}}))
+	t.Cleanup(func() { collector.Unregister("TEST_RANGE") })
+
+	rollup := func(avg float64) string {
+		return fmt.Sprintf(`{"v":{"min":%v,"max":%v,"avg":%v,"count":60}}`, avg, avg, avg)
+	}
+
+	putValue(t, db, "TEST_RANGE_1H", base, rollup(1))
+	putValue(t, db, "TEST_RANGE_1H", base.Add(time.Hour), rollup(2))
+	putValue(t, db, "TEST_RANGE_1H", base.Add(2*time.Hour), rollup(99))
+	putValue(t, db, "TEST_RANGE_1M", base.Add(2*time.Hour), rollup(3))
+	putValue(t, db, "TEST_RANGE_1M", base.Add(2*time.Hour+time.Minute), rollup(4))
+	putValue(t, db, "TEST_RANGE", base.Add(2*time.Hour+2*time.Minute), `{"v":5}`)
+	putValue(t, db, "TEST_RANGE", base.Add(2*time.Hour+150*time.Second), `{"v":6}`)
+
+	return db
+}
+
+// fakeDevice type - collector answering the errors of results in turn, or one
+// value when the error is nil
+type fakeDevice struct {
+	name    string
+	results []error
+}
+
+func (f *fakeDevice) Name() string {
+	return f.name
+}
+
+func (f *fakeDevice) Variables() []collector.Variable {
+	return []collector.Variable{{Code: "v@" + f.name, Key: "v"}}
+}
+
+func (f *fakeDevice) Collect(ctx context.Context) ([]collector.Point, error) {
+	err := f.results[0]
+	f.results = f.results[1:]
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []collector.Point{{Key: "v", Value: 1}}, nil
+}
+
+// fakeBacklog type - collector with readings of their own time, all due at once
+type fakeBacklog struct {
+	name     string
+	readings []collector.Reading
+}
+
+func (f *fakeBacklog) Name() string {
+	return f.name
+}
+
+func (f *fakeBacklog) Variables() []collector.Variable {
+	return []collector.Variable{{Code: "v@" + f.name, Key: "v"}}
+}
+
+func (f *fakeBacklog) Collect(ctx context.Context) ([]collector.Point, error) {
+	return nil, nil
+}
+
+func (f *fakeBacklog) CollectDue(ctx context.Context) ([]collector.Reading, error) {
+	return f.readings, nil
+}
+
+// metricValue - Return the exposed value of metric name with labels, or empty
+func metricValue(name string, labels string) string {
+	prefix := fmt.Sprintf("%s{%s} ", name, labels)
+
+	for _, line := range strings.Split(metrics.Expose(), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimPrefix(line, prefix)
+		}
+	}
+
+	return ""
+}
+
+func TestGetRange(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	db := rangeDB(t, base)
+
+	tests := []struct {
+		name    string
+		from    time.Duration
+		to      time.Duration
+		want    []float64
+		wantErr error
+	}{
+		{"raw and rollup tiers", 0, 3 * time.Hour, []float64{1, 2, 3, 4, 5, 6}, nil},
+		{"raw and minute tier", 2 * time.Hour, 3 * time.Hour, []float64{3, 4, 5, 6}, nil},
+		{"raw tier only", 2*time.Hour + 2*time.Minute, 3 * time.Hour, []float64{5, 6}, nil},
+		{"hour tier only", 0, time.Hour, []float64{1, 2}, nil},
+		{"from later than to", 3 * time.Hour, 0, nil, ErrReversedRange},
+		{"empty range", 4 * time.Hour, 5 * time.Hour, nil, ErrNoMetrics},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := GetRange(db, base.Add(tt.from), base.Add(tt.to), "r1")
+
+			if err != tt.wantErr {
+				t.Fatalf("GetRange error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if len(series) != 1 {
+				t.Fatalf("got %d series, want 1", len(series))
+			}
+
+			got := series[0].Values()
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLastN(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	db := rangeDB(t, base)
+
+	// Errors are matched by a part of their message
+	tests := []struct {
+		name    string
+		codeStr string
+		want    []float64
+		wantErr string
+	}{
+		{name: "last points", codeStr: "2,r1", want: []float64{5, 6}},
+		{name: "more than stored", codeStr: "3,r1", wantErr: "exceed total size"},
+		{name: "not a number", codeStr: "ten,r1", wantErr: "positive number"},
+		{name: "zero", codeStr: "0,r1", wantErr: "positive number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := GetLastN(db, tt.codeStr)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("GetLastN(%q) error = %v, want %q", tt.codeStr, err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetLastN(%q) error = %v", tt.codeStr, err)
+			}
+			if got := series[0].Values(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeviceCodes(t *testing.T) {
+	db := openTestDB(t)
+
+	// Device of an older session, known only from its table and stored variables
+	table := database.SubTable("SAMPLES", "gh1")
+
+	if err := database.CreateTable(db, table); err != nil {
+		t.Fatalf("CreateTable error = %v", err)
+	}
+
+	stored := collector.NewStored(table, []collector.Variable{{Code: "t@gh1", Key: "temperature"}})
+
+	collector.Register(stored)
+	defer collector.Unregister(table)
+
+	tests := []struct {
+		name    string
+		codes   string
+		device  string
+		want    string
+		wantErr bool
+	}{
+		{name: "every variable", device: "gh1", want: "t@gh1"},
+		{name: "code of the device", codes: "t", device: "gh1", want: "t@gh1"},
+		{name: "code of another device", codes: "t@gh2", device: "gh1", want: ""},
+		{name: "unknown device", codes: "t", device: "gh2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeviceCodes(db, tt.codes, tt.device)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeviceCodes error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DeviceCodes(%q, %q) = %q, want %q", tt.codes, tt.device, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreCollectorMetrics(t *testing.T) {
+	// Disconnections are logged into log.txt of the working directory
+	wd, err := os.Getwd()
+
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		name      string
+		results   []error
+		connected string
+		writes    string
+		failures  string
+		missed    string
+		durations string
+		value     string
+	}{
+		{
+			name:      "answered",
+			results:   []error{nil},
+			connected: "1",
+			writes:    "1",
+			durations: "1",
+			value:     "1",
+		},
+		{
+			name:      "no answer",
+			results:   []error{collector.ErrNoAnswer, nil},
+			connected: "1",
+			writes:    "1",
+			failures:  "1",
+			missed:    "1",
+			durations: "2",
+			value:     "1",
+		},
+		{
+			name:      "disconnected",
+			results:   []error{nil, collector.ErrDisconnected, collector.ErrDisconnected},
+			connected: "0",
+			writes:    "1",
+			failures:  "2",
+			durations: "3",
+			value:     "1",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			device := &fakeDevice{name: fmt.Sprintf("TEST_%d", i), results: tt.results}
+
+			if err := database.CreateTable(db, device.Name()); err != nil {
+				t.Fatalf("CreateTable error = %v", err)
+			}
+
+			for range tt.results {
+				StoreCollector(context.Background(), db, device)
+			}
+
+			table := metrics.Labels("table", device.Name())
+
+			got := []string{
+				metricValue(metrics.Name("connected"), table),
+				metricValue(metrics.Name("writes_total"), table),
+				metricValue(metrics.Name("write_failures_total"), table),
+				metricValue(metrics.Name("missed_readings_total"), metrics.Labels("table", device.Name(), "reason", "no_answer")),
+				metricValue(metrics.Name("collection_duration_seconds")+"_count", table),
+				metricValue(metrics.Name("value"), metrics.Labels("table", device.Name(), "variable", "v", "label", "")),
+			}
+			want := []string{tt.connected, tt.writes, tt.failures, tt.missed, tt.durations, tt.value}
+
+			for j, name := range []string{"connected", "writes", "failures", "missed", "durations", "value"} {
+				if got[j] != want[j] {
+					t.Errorf("%s = %q, want %q", name, got[j], want[j])
+				}
+			}
+		})
+	}
+}
+
+func TestStoreCollectorBacklog(t *testing.T) {
+	db := openTestDB(t)
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+
+	// Empty readings are not stored
+	backlog := &fakeBacklog{name: "TEST_BACKLOG", readings: []collector.Reading{
+		{Time: base, Points: []collector.Point{{Key: "v", Value: 1}}},
+		{Time: base.Add(time.Second), Points: nil},
+		{Time: base.Add(2 * time.Second), Points: []collector.Point{{Key: "v", Value: 2}}},
+		{Time: base.Add(3 * time.Second), Points: []collector.Point{{Key: "v", Value: 3}}},
+	}}
+
+	if err := database.CreateTable(db, backlog.Name()); err != nil {
+		t.Fatalf("CreateTable error = %v", err)
+	}
+	if err := StoreCollector(context.Background(), db, backlog); err != nil {
+		t.Fatalf("StoreCollector error = %v", err)
+	}
+
+	var got []string
+
+	err := db.View(func(tx *bolt.Tx) error {
+		return database.Table(tx, backlog.Name()).ForEach(func(k, v []byte) error {
+			got = append(got, fmt.Sprintf("%v %s", database.KeyTime(k).Sub(base), v))
+			return nil
+		})
+	})
+
+	if err != nil {
+		t.Fatalf("read entries: %v", err)
+	}
+
+	want := []string{`0s {"v":1}`, `2s {"v":2}`, `3s {"v":3}`}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	if got := metricValue(metrics.Name("writes_total"), metrics.Labels("table", backlog.Name())); got != "3" {
+		t.Errorf("writes = %q, want %q", got, "3")
+	}
+}
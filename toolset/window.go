@@ -0,0 +1,134 @@
+/*
+	Date	:	18/10/2026
+	File	:	window.go
+	Overview: 	Window provides downsampling queries. Stored points are grouped
+				into fixed time windows (10s, 1m, 1h, ...) and an aggregate
+				function is applied to each window. Results can be printed as
+				a table or as JSON.
+*/
+
+package toolset
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
+
+	"github.com/boltdb/bolt"
+)
+
+// GetWindowed take a database to lookup, a time range, the window size, an
+// aggregate function (see AggregateFunctions) and a code string with variables.
+// It returns one series per known variable with a point per time window that
+// has values, timed at the window start. Points are read from the coarsest rollup
+// tier that fits the window, see readCoarsest.
+func GetWindowed(db *bolt.DB, from time.Time, to time.Time, window time.Duration, fn string, codeStr string) ([]Series, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("[Toolset] - Window must be bigger than zero")
+	}
+
+	// Validate function before reading anything
+	if _, err := (Aggregate{}).Get(fn); err != nil {
+		return nil, err
+	}
+
+	codeArray := strings.Split(codeStr, ",")
+
+	var series []Series
+
+	err := db.View(func(tx *bolt.Tx) error {
+		for _, code := range knownCodes(codeArray) {
+			points := readCoarsest(tx, code, from, to, window)
+			series = append(series, downsample(code, points, window, fn))
+		}
+		return nil
+	})
+
+	if err != nil {
+		WriteToLog(fmt.Sprintf("%s \t || [Database] \t Error while getting windowed metrics \n", GetFormatedTime()))
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// downsample - groups rollup points into windows and applies the aggregate
+// function fn to every window. Count, min, max, sum and mean are exact for any
+// tier; other functions are computed over the averages of the points, so they
+// are only approximate when points are rollups of several values.
+func downsample(code string, points []rollupPoint, window time.Duration, fn string) Series {
+	windowed := Series{Variable: code}
+
+	// Points are sorted, so every window is a run of consecutive points
+	for i := 0; i < len(points); {
+		start := points[i].Time.Truncate(window)
+
+		var merged database.Rollup
+		var averages []float64
+
+		for ; i < len(points) && points[i].Time.Truncate(window).Equal(start); i++ {
+			merged = merged.Merge(points[i].Rollup)
+			averages = append(averages, points[i].Rollup.Avg)
+		}
+
+		var value float64
+
+		switch strings.ToLower(fn) {
+		case "count":
+			value = float64(merged.Count)
+		case "min":
+			value = merged.Min
+		case "max":
+			value = merged.Max
+		case "sum":
+			value = merged.Avg * float64(merged.Count)
+		case "mean", "avg":
+			value = merged.Avg
+		default:
+			value, _ = ComputeAggregate(code, averages).Get(fn)
+		}
+
+		windowed.Points = append(windowed.Points, Point{Time: start, Value: value})
+	}
+
+	return windowed
+}
+
+// DecodeWindow splits a window code string like "6h,1m,mean,table,c,r" into the
+// span to look back, window size, aggregate function, output format and variables
+func DecodeWindow(windowStr string) (time.Duration, time.Duration, string, string, string, error) {
+	codeArray := strings.SplitN(windowStr, ",", 5)
+
+	if len(codeArray) < 5 {
+		return 0, 0, "", "", "", fmt.Errorf("[Toolset] - Window query needs a span, a window, a function, a format and at least one variable")
+	}
+
+	span, err := time.ParseDuration(codeArray[0])
+
+	if err != nil {
+		return 0, 0, "", "", "", fmt.Errorf("[Toolset] - Invalid span %q: %v", codeArray[0], err)
+	}
+
+	window, err := time.ParseDuration(codeArray[1])
+
+	if err != nil {
+		return 0, 0, "", "", "", fmt.Errorf("[Toolset] - Invalid window %q: %v", codeArray[1], err)
+	}
+
+	return span, window, codeArray[2], codeArray[3], codeArray[4], nil
+}
+
+// PrintWindows - Prints windowed series as a table, one row per window, or, with
+// format json, as JSON
+func PrintWindows(series []Series, format string) error {
+	if strings.ToLower(format) == "json" {
+		return printJSON(series)
+	}
+
+	// Windows of every series start at the same instants, so join them exactly
+	printRows(Join(series, 0), series)
+
+	return nil
+}
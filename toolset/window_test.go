@@ -0,0 +1,80 @@
+/*
+	Date	:	18/10/2026
+	File	:	window_test.go
+	Overview: 	Tests of the windows points are grouped into and of the
+				statistic computed for each one.
+*/
+
+package toolset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itsMeDacarvalho/ubiwhere-challenge/database"
+)
+
+// rawPoint - Return a raw point with value, taken seconds after base
+func rawPoint(base time.Time, seconds int, value float64) rollupPoint {
+	return rollupPoint{
+		Time:   base.Add(time.Duration(seconds) * time.Second),
+		Rollup: database.Rollup{Min: value, Max: value, Avg: value, Count: 1},
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Windows of 10s: 1 and 2 share the first, 3 starts the second on its edge
+	raw := []rollupPoint{rawPoint(base, 0, 1), rawPoint(base, 9, 2), rawPoint(base, 10, 3), rawPoint(base, 25, 4)}
+
+	// Rollups of two values each (0 and 4, 1 and 3) inside the same window
+	rollups := []rollupPoint{
+		{Time: base, Rollup: database.Rollup{Min: 0, Max: 4, Avg: 2, Count: 2}},
+		{Time: base.Add(time.Second), Rollup: database.Rollup{Min: 1, Max: 3, Avg: 2, Count: 2}},
+	}
+
+	tests := []struct {
+		name   string
+		points []rollupPoint
+		fn     string
+		want   []float64
+	}{
+		{"count", raw, "count", []float64{2, 1, 1}},
+		{"min", raw, "min", []float64{1, 3, 4}},
+		{"max", raw, "max", []float64{2, 3, 4}},
+		{"sum", raw, "sum", []float64{3, 3, 4}},
+		{"mean", raw, "mean", []float64{1.5, 3, 4}},
+		{"avg in capitals", raw, "AVG", []float64{1.5, 3, 4}},
+		{"stddev", raw, "stddev", []float64{0.5, 0, 0}},
+		{"median", raw, "median", []float64{1.5, 3, 4}},
+		{"p95", raw, "p95", []float64{1.95, 3, 4}},
+		{"p99", raw, "p99", []float64{1.99, 3, 4}},
+		{"count of rollups", rollups, "count", []float64{4}},
+		{"min of rollups", rollups, "min", []float64{0}},
+		{"max of rollups", rollups, "max", []float64{4}},
+		{"sum of rollups", rollups, "sum", []float64{8}},
+		{"mean of rollups", rollups, "mean", []float64{2}},
+		{"stddev of rollups is over averages", rollups, "stddev", []float64{0}},
+		{"no points", nil, "mean", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := downsample("v", tt.points, 10*time.Second, tt.fn)
+
+			if len(got.Points) != len(tt.want) {
+				t.Fatalf("got %d windows, want %d", len(got.Points), len(tt.want))
+			}
+
+			for i, p := range got.Points {
+				if start := base.Add(time.Duration(i) * 10 * time.Second); !p.Time.Equal(start) {
+					t.Errorf("window %d starts at %v, want %v", i, p.Time, start)
+				}
+				if !closeTo(p.Value, tt.want[i]) {
+					t.Errorf("window %d = %v, want %v", i, p.Value, tt.want[i])
+				}
+			}
+		})
+	}
+}